@@ -3,12 +3,16 @@ package binlog
 
 import (
 	"errors"
+	"fmt"
 	"io"
 )
 
+// Positions (Seek, Read) stay the same as bytes are appended at the
+// end and discarded from the start; start is the position of buf[0]
 type AppendableBuffer struct {
-	buf []byte
-	off int
+	buf   []byte
+	off   int
+	start int64
 }
 
 func NewAppendableBuffer(buf []byte) *AppendableBuffer {
@@ -18,15 +22,47 @@ func NewAppendableBuffer(buf []byte) *AppendableBuffer {
 	}
 }
 
+// Number of bytes held, not counting discarded ones
 func (b *AppendableBuffer) Length() int {
 	return len(b.buf)
 }
 
+// Position of the first byte that hasn't been discarded
+func (b *AppendableBuffer) Start() int64 {
+	return b.start
+}
+
 func (b *AppendableBuffer) Append(p []byte) {
 	b.buf = append(b.buf, p...)
 }
 
+// Drops the bytes before position. The memory is reused for the
+// bytes appended after, so the buffer only grows as large as what
+// is held at once.
+func (b *AppendableBuffer) Discard(position int64) {
+	n := int(position - b.start)
+	if n <= 0 {
+		return
+	}
+
+	if n > len(b.buf) {
+		n = len(b.buf)
+	}
+
+	b.buf = append(b.buf[:0], b.buf[n:]...)
+	b.start += int64(n)
+
+	b.off -= n
+	if b.off < 0 {
+		b.off = 0
+	}
+}
+
 func (b *AppendableBuffer) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
 	if b.off >= len(b.buf) {
 		return 0, io.EOF
 	}
@@ -43,20 +79,24 @@ func (b *AppendableBuffer) Seek(offset int64, whence int) (int64, error) {
 		newPosition = 0
 
 	case 1:
-		newPosition = int64(b.off)
+		newPosition = b.start + int64(b.off)
 
 	case 2:
-		newPosition = int64(len(b.buf) - 1)
+		newPosition = b.start + int64(len(b.buf))
 
 	default:
-		return int64(b.off), errors.New("Invalid whence passed to Seek")
+		return b.start + int64(b.off), errors.New("Invalid whence passed to Seek")
 	}
 
 	newPosition += offset
-	if newPosition < 0 || newPosition > int64(len(b.buf)) {
-		return int64(b.off), io.EOF
+	if newPosition < b.start && newPosition >= 0 {
+		return b.start + int64(b.off), fmt.Errorf("%w: position %v is before %v", ErrEventReleased, newPosition, b.start)
+	}
+
+	if newPosition < 0 || newPosition > b.start+int64(len(b.buf)) {
+		return b.start + int64(b.off), io.EOF
 	}
 
-	b.off = int(newPosition)
+	b.off = int(newPosition - b.start)
 	return newPosition, nil
 }
@@ -0,0 +1,33 @@
+package binlog
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppendableBufferDiscard(t *testing.T) {
+	b := NewAppendableBuffer([]byte{0, 1, 2, 3, 4, 5})
+	b.Discard(4)
+	b.Append([]byte{6, 7})
+
+	assert.Equal(t, int64(4), b.Start())
+	assert.Equal(t, 4, b.Length())
+
+	position, err := b.Seek(5, io.SeekStart)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), position)
+
+	p := make([]byte, 2)
+	_, err = b.Read(p)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{5, 6}, p)
+
+	end, err := b.Seek(0, io.SeekEnd)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), end)
+
+	_, err = b.Seek(3, io.SeekStart)
+	assert.ErrorIs(t, err, ErrEventReleased)
+}
@@ -1,40 +1,68 @@
 package binlog
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/granicus/mysql-binlog-go/deserialization"
 )
 
-type EventDataDeserializeFunc func(*EventHeader) EventData
+type EventDataDeserializeFunc func(*EventHeader) (EventData, error)
 
 type Binlog struct {
 	TableMapCollection map[uint64]*TableMapEvent
 	reader             io.ReadSeeker
 	logVersion         uint8
+	formatDescription  *FormatDescriptionEvent
+	verifyChecksums    bool
+	schemaProvider     SchemaProvider
 	bytesLength        int64
 	events             []*Event
+
+	// File name for event positions, empty if not reading a file
+	name string
+
+	// The file was closed, its events can't be decoded any more
+	closed bool
+
+	// See holdEvents
+	holding  bool
+	heldFrom int64
+
+	// Start of the event being decoded
+	eventStart int64
+
+	// Where the next event not in events starts, and the index in
+	// events of the next one Next returns
+	nextEventPosition int64
+	nextEventIndex    int
 }
 
-func NewBinlog(r io.ReadSeeker) *Binlog {
+// Events are read as they are asked for (see Next), only the
+// FORMAT_DESCRIPTION_EVENT at the start is read here
+func NewBinlog(r io.ReadSeeker) (*Binlog, error) {
 	b := &Binlog{
 		TableMapCollection: make(map[uint64]*TableMapEvent),
 		reader:             r,
+		formatDescription:  defaultFormatDescription(),
 		bytesLength:        -1,
 		events:             []*Event{},
 	}
 
-	b.findLogVersion()
-	b.indexEvents()
+	if err := b.findLogVersion(); err != nil {
+		return nil, err
+	}
 
-	return b
+	return b, nil
 }
 
-func OpenBinlog(filepath string) (*Binlog, error) {
-	file, err := os.OpenFile(filepath, os.O_RDONLY, 0)
+func OpenBinlog(path string) (*Binlog, error) {
+	file, err := os.OpenFile(path, os.O_RDONLY, 0)
 
 	if err != nil {
 		return nil, err
@@ -46,17 +74,75 @@ func OpenBinlog(filepath string) (*Binlog, error) {
 		return nil, err
 	}
 
-	b := NewBinlog(file)
+	b, err := NewBinlog(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
 	b.bytesLength = stat.Size()
+	b.name = filepath.Base(path)
 	return b, nil
 }
 
+// Opens position.File in dir, Next starts with the event at
+// position.Offset (see position.go)
+func OpenBinlogAt(dir string, position Position) (*Binlog, error) {
+	b, err := OpenBinlog(filepath.Join(dir, position.File))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.skipTo(position.Offset, nil); err != nil {
+		b.close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Closes the file opened by OpenBinlog, if that's where the events
+// are coming from
+func (b *Binlog) close() error {
+	b.closed = true
+
+	if closer, ok := b.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// Where the event after the last one Next returned starts
+func (b *Binlog) nextOffset() int64 {
+	if b.nextEventIndex < len(b.events) {
+		return b.events[b.nextEventIndex].Position()
+	}
+
+	return b.nextEventPosition
+}
+
+// The most recently read FORMAT_DESCRIPTION_EVENT, or the
+// MySQL 5.6 defaults if one hasn't been read yet
+func (b *Binlog) FormatDescription() *FormatDescriptionEvent {
+	return b.formatDescription
+}
+
+// Reads the rest of the file. Stops quietly at the first event that
+// can't be read, use Next to see the error.
 func (b *Binlog) Events() []*Event {
+	b.indexEvents()
 	return b.events
 }
 
 func (b *Binlog) Event(i int) *Event {
-	if len(b.events) > i || i < 0 {
+	for i >= len(b.events) {
+		if _, err := b.readEvent(); err != nil {
+			return nil
+		}
+	}
+
+	if i < 0 {
 		return nil
 	}
 
@@ -78,8 +164,10 @@ func (b *Binlog) EventsAfter(position int64) []*Event {
 		position = 4
 	}
 
+	b.indexEvents()
+
 	firstIndex := b.eventIndexAtPosition(position)
-	if firstIndex < 0 || len(b.events)-1 > firstIndex {
+	if firstIndex < 0 {
 		return []*Event{}
 	}
 
@@ -106,8 +194,10 @@ func (b *Binlog) SetInputStream(r io.ReadSeeker) {
 	b.bytesLength = -1
 }
 
-func (b *Binlog) deserializeEventHeader(startPosition int64) *EventHeader {
-	fatalErr(b.SetPosition(startPosition))
+func (b *Binlog) deserializeEventHeader(startPosition int64) (*EventHeader, error) {
+	if err := b.SetPosition(startPosition); err != nil {
+		return nil, err
+	}
 
 	return ReadEventHeader(b.reader)
 }
@@ -119,38 +209,125 @@ func (b *Binlog) eventDataDeserializeFuncFor(eventType MysqlBinlogEventType) Eve
 		WRITE_ROWS_EVENTv2, UPDATE_ROWS_EVENTv2, DELETE_ROWS_EVENTv2:
 		return b.DeserializeRowsEvent
 
+	case FORMAT_DESCRIPTION_EVENT:
+		return b.DeserializeFormatDescriptionEvent
+
+	case QUERY_EVENT:
+		return b.DeserializeQueryEvent
+
+	case ROTATE_EVENT:
+		return b.DeserializeRotateEvent
+
+	case XID_EVENT:
+		return b.DeserializeXidEvent
+
+	case GTID_EVENT, ANONYMOUS_GTID_EVENT:
+		return b.DeserializeGtidEvent
+
+	case PREVIOUS_GTIDS_EVENT:
+		return b.DeserializePreviousGtidsEvent
+
 	case TABLE_MAP_EVENT:
 		return b.DeserializeTableMapEvent
 
 	default:
-		fmt.Println("unsupported event data deserialization:", eventType)
+		// Events we don't decode (ROWS_QUERY, HEARTBEAT, ...) have
+		// empty data, check Event.Type() before Data()
+		return func(header *EventHeader) (EventData, error) { return &struct{}{}, nil }
+	}
+}
 
-		return func(header *EventHeader) EventData { return &struct{}{} }
+func (b *Binlog) deserializeEventData(startPosition int64, header *EventHeader) (EventData, error) {
+	b.eventStart = startPosition
+
+	if b.verifyChecksums {
+		if err := b.verifyEventChecksum(startPosition, header); err != nil {
+			return nil, newEventError(startPosition, header.Type, err)
+		}
 	}
 
-	return nil
+	if err := b.SetPosition(startPosition + int64(b.formatDescription.HeaderLength)); err != nil {
+		return nil, err
+	}
+
+	data, err := b.eventDataDeserializeFuncFor(header.Type)(header)
+	if err != nil {
+		return nil, newEventError(startPosition, header.Type, err)
+	}
+
+	return data, b.SetPosition(b.eventEnd(header))
+}
+
+// End of the event being decoded, checksum included. This goes by
+// the event's length rather than NextPosition, which is a position
+// in the server's file and doesn't line up with network streams.
+func (b *Binlog) eventEnd(header *EventHeader) int64 {
+	return b.eventStart + int64(header.Length)
 }
 
-func (b *Binlog) deserializeEventData(startPosition int64, header *EventHeader) EventData {
-	fatalErr(b.SetPosition(startPosition + 19))
+// Table ids are 4 bytes when the post-header is 6 bytes long
+// (MySQL < 5.1.4) and 6 bytes otherwise. Returns the id and
+// how many bytes it took up.
+func (b *Binlog) readTableId(eventType MysqlBinlogEventType) (uint64, int, error) {
+	length := 6
+	if b.formatDescription.PostHeaderLength(eventType) == 6 {
+		length = 4
+	}
+
+	tableIdBytes, err := deserialization.ReadBytes(b.reader, length)
+	if err != nil {
+		return 0, 0, err
+	}
 
-	data := b.eventDataDeserializeFuncFor(header.Type)(header)
-	b.SetPosition(int64(header.NextPosition))
-	return data
+	// Pad to 8, read as uint64
+	tableIdBytes = append(tableIdBytes, make([]byte, 8-length)...)
+
+	return binary.LittleEndian.Uint64(tableIdBytes), length, nil
 }
 
-func (b *Binlog) findTableMapEvent(tableId uint64) *TableMapEvent {
+// Skips any post-header fields newer than the ones we know about
+func (b *Binlog) skipPostHeader(eventType MysqlBinlogEventType, consumed int) error {
+	remaining := int(b.formatDescription.PostHeaderLength(eventType)) - consumed
+	if remaining <= 0 {
+		return nil
+	}
+
+	return b.Skip(int64(remaining))
+}
+
+// Number of bytes between the reader and the end of the current
+// event's data (i.e. not counting the checksum)
+func (b *Binlog) remainingEventLength(header *EventHeader) (int64, error) {
+	currentPosition, err := b.GetPosition()
+	if err != nil {
+		return 0, err
+	}
+
+	checksumLength := int64(b.formatDescription.ChecksumAlgorithm.Length())
+	remaining := b.eventEnd(header) - checksumLength - currentPosition
+	if remaining < 0 {
+		return 0, fmt.Errorf("%w: read %v bytes past the end of the event", ErrMalformedEvent, -remaining)
+	}
+
+	return remaining, nil
+}
+
+func (b *Binlog) findTableMapEvent(tableId uint64) (*TableMapEvent, error) {
 	for _, event := range b.events {
 		if event.Type() == TABLE_MAP_EVENT && event.data == nil {
-			data := event.Data().(*TableMapEvent)
+			data, err := event.Data()
+			if err != nil {
+				return nil, err
+			}
 
-			if data.TableId == tableId {
-				return data
+			tableMap := data.(*TableMapEvent)
+			if tableMap.TableId == tableId {
+				return tableMap, nil
 			}
 		}
 	}
 
-	return nil
+	return nil, fmt.Errorf("%w %v", ErrMissingTableMap, tableId)
 }
 
 /*
@@ -187,92 +364,154 @@ moved soon, along with this message.
 // Determines the binlog version from the first event
 // http://dev.mysql.com/doc/internals/en/determining-binary-log-version.html
 func determineLogVersion(typeCode MysqlBinlogEventType, length uint32) uint8 {
-	if typeCode != START_EVENT_V3 && typeCode != FORMAT_DESCRIPTION_EVENT {
-		return 3
-	} else if typeCode == START_EVENT_V3 {
+	switch typeCode {
+	case START_EVENT_V3:
 		if length < 75 {
 			return 1
-		} else {
-			return 3
 		}
-	} else if typeCode == FORMAT_DESCRIPTION_EVENT {
+
+		return 3
+
+	case FORMAT_DESCRIPTION_EVENT:
 		return 4
-	} else {
-		log.Fatal(fmt.Sprintf("Could not determine log version from: [%v, %v]", typeCode, length))
 	}
 
-	return 0
+	return 3
 }
 
 // Finds log version and move reader to end of first event
 // assumes reader is still at beginning of file
-func (b *Binlog) findLogVersion() {
+func (b *Binlog) findLogVersion() error {
 	magic, err := deserialization.ReadBytes(b.reader, 4)
-
 	if err != nil {
-		log.Fatal("Something went wrong when reading magic number:", err)
+		return fmt.Errorf("%w: %v", ErrInvalidMagic, err)
 	}
 
 	if !checkBinlogMagic(magic) {
-		log.Fatal("Binlog magic number was not correct. This is probably not a binlog.")
+		return ErrInvalidMagic
+	}
+
+	header, err := ReadEventHeader(b.reader)
+	if err != nil {
+		return newEventError(int64(MAGIC_BYTES_LENGTH), UNKOWN_EVENT, err)
 	}
 
-	header := ReadEventHeader(b.reader)
 	b.logVersion = determineLogVersion(header.Type, header.Length)
 
 	// From here on out, we assume v4 events (for now)
 	// this just errors out if it isn't v4
 	if b.logVersion != 4 {
-		panic("Sorry, this only supports v4 logs right now.")
+		return fmt.Errorf("%w: v%v (only v4 logs are supported)", ErrUnsupportedLogVersion, b.logVersion)
+	}
+
+	// v4 logs always start with a FORMAT_DESCRIPTION_EVENT
+	b.eventStart = int64(MAGIC_BYTES_LENGTH)
+	if _, err = b.DeserializeFormatDescriptionEvent(header); err != nil {
+		return newEventError(b.eventStart, header.Type, err)
 	}
 
-	fatalErr(b.SetPosition(int64(header.NextPosition)))
+	b.nextEventPosition = b.eventEnd(header)
+	return b.SetPosition(b.nextEventPosition)
 }
 
-func (b *Binlog) indexEvent() error {
-	startPosition, err := b.GetPosition()
-	if err != nil {
-		return err
+// Size of the underlying reader, -1 if it can't be determined
+func (b *Binlog) streamLength() int64 {
+	if b.bytesLength >= 0 {
+		return b.bytesLength
 	}
 
-	// Skip to Type
-	err = b.Skip(4)
+	position, err := b.GetPosition()
 	if err != nil {
-		return err
+		return -1
 	}
 
-	typeByte, err := deserialization.ReadByte(b.reader)
+	end, err := b.reader.Seek(0, io.SeekEnd)
 	if err != nil {
-		return err
+		return -1
 	}
 
-	eventType := MysqlBinlogEventType(typeByte)
+	if err = b.SetPosition(position); err != nil {
+		return -1
+	}
 
-	// Skip server id and length
-	err = b.Skip(8)
-	if err != nil {
-		return err
+	return end
+}
+
+// Reads the header of the event at nextEventPosition and adds the
+// event to events. Returns io.EOF when there are no more events,
+// and ErrTruncatedEvent (in an *EventError) when the last event
+// is incomplete.
+//
+// FORMAT_DESCRIPTION_EVENTs and TABLE_MAP_EVENTs are decoded right
+// away, every event after them depends on them. Everything else is
+// decoded when Event.Data is called.
+func (b *Binlog) readEvent() (*Event, error) {
+	position := b.nextEventPosition
+	if err := b.SetPosition(position); err != nil {
+		return nil, err
 	}
 
-	nextPosition, err := deserialization.ReadUint32(b.reader)
+	headerBytes := make([]byte, EVENT_HEADER_LENGTH)
+	n, err := io.ReadFull(b.reader, headerBytes)
+	if n == 0 && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+		return nil, io.EOF
+	}
 	if err != nil {
-		return err
+		return nil, newEventError(position, UNKOWN_EVENT, err)
 	}
 
-	// Skip the difference between where we should be and where we started, minus what we already read
-	err = b.SetPosition(int64(nextPosition))
+	header, err := ReadEventHeader(bytes.NewReader(headerBytes))
 	if err != nil {
-		return err
+		return nil, newEventError(position, UNKOWN_EVENT, err)
 	}
 
-	b.events = append(b.events, newIndexedEvent(b, eventType, startPosition))
+	if header.Length < uint32(EVENT_HEADER_LENGTH) {
+		return nil, newEventError(position, header.Type,
+			fmt.Errorf("%w: event length %v is shorter than its header", ErrMalformedEvent, header.Length))
+	}
 
-	return nil
+	end := position + int64(header.Length)
+	if length := b.streamLength(); length >= 0 && end > length {
+		return nil, newEventError(position, header.Type,
+			fmt.Errorf("%w: event ends at %v, past the end of the stream at %v", ErrTruncatedEvent, end, length))
+	}
+
+	event := newIndexedEvent(b, header.Type, position)
+	event.header = header
+
+	switch header.Type {
+	case FORMAT_DESCRIPTION_EVENT, TABLE_MAP_EVENT:
+		if _, err = event.Data(); err != nil {
+			return nil, err
+		}
+	}
+
+	b.events = append(b.events, event)
+	b.nextEventPosition = end
+
+	return event, nil
 }
 
 func (b *Binlog) indexEvents() {
 	var err error
 	for err == nil {
-		err = b.indexEvent()
+		_, err = b.readEvent()
+	}
+}
+
+// Returns the next event of the file, or io.EOF after the last one.
+// Binlog implements EventReader.
+func (b *Binlog) Next(ctx context.Context) (*Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+
+	if b.nextEventIndex >= len(b.events) {
+		if _, err := b.readEvent(); err != nil {
+			return nil, err
+		}
+	}
+
+	b.nextEventIndex++
+	return b.events[b.nextEventIndex-1], nil
 }
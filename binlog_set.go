@@ -0,0 +1,355 @@
+package binlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/granicus/mysql-binlog-go/gtid"
+)
+
+/*
+BINLOG SETS
+===========
+
+MySQL numbers its binlogs (mysql-bin.000001, mysql-bin.000002, ...)
+and lists the ones it still has, oldest first, in an index file
+(mysql-bin.index), one path per line. Relative paths are relative
+to the data directory, which is where the index file lives:
+
+./mysql-bin.000001
+./mysql-bin.000002
+
+A BinlogSet reads the files in that order as a single stream of
+events. Every file ends with a ROTATE_EVENT (returned like any other
+event) except the one the server is writing, which is last.
+
+Positions are still a file name and an offset (see position.go),
+Compare orders them across the set. The list of files is read once,
+when the set is opened.
+
+*/
+
+type BinlogFile struct {
+	Name string
+	Path string
+}
+
+type BinlogFileMetadata struct {
+	Size           int64
+	FirstTimestamp uint32 // of the FORMAT_DESCRIPTION_EVENT
+	LastTimestamp  uint32
+
+	// GTIDs executed before the file, nil without GTIDs (no
+	// PREVIOUS_GTIDS_EVENT)
+	PreviousGtids *gtid.GTIDSet
+
+	// The server stopped writing to the file and closed it (the
+	// LOG_EVENT_BINLOG_IN_USE_F flag of the format description has
+	// been cleared). False for the file being written to, or one
+	// left behind by a crash.
+	Closed bool
+}
+
+// Reads through the event headers of the file, only PREVIOUS_GTIDS
+// and the events needed to read the rest are decoded
+func (f *BinlogFile) Metadata() (*BinlogFileMetadata, error) {
+	b, err := OpenBinlog(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer b.close()
+
+	header, err := b.deserializeEventHeader(int64(MAGIC_BYTES_LENGTH))
+	if err != nil {
+		return nil, newEventError(int64(MAGIC_BYTES_LENGTH), FORMAT_DESCRIPTION_EVENT, err)
+	}
+
+	m := &BinlogFileMetadata{
+		Size:           b.bytesLength,
+		FirstTimestamp: header.Timestamp,
+		LastTimestamp:  header.Timestamp,
+		Closed:         binary.LittleEndian.Uint16(header.Flag[:])&LOG_EVENT_BINLOG_IN_USE_F == 0,
+	}
+
+	for {
+		event, err := b.readEvent()
+		if err == io.EOF {
+			break
+		}
+
+		// The server may be in the middle of writing the last event
+		if errors.Is(err, ErrTruncatedEvent) && !m.Closed {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if event.header.Timestamp > m.LastTimestamp {
+			m.LastTimestamp = event.header.Timestamp
+		}
+
+		if event.Type() == PREVIOUS_GTIDS_EVENT && m.PreviousGtids == nil {
+			data, err := event.Data()
+			if err != nil {
+				return nil, err
+			}
+
+			m.PreviousGtids = data.(*PreviousGtidsEvent).Set
+		}
+
+		// Only the headers are needed, don't hold on to the events
+		b.events = b.events[:0]
+	}
+
+	return m, nil
+}
+
+type BinlogSet struct {
+	Files []*BinlogFile
+
+	// The file being read, opened by Next
+	current   *Binlog
+	fileIndex int
+
+	// Where Next reopens the current file after Close, 0 for the
+	// start
+	resumeOffset int64
+}
+
+// Reads the list of files from a MySQL index file (e.g.
+// /var/lib/mysql/mysql-bin.index)
+func OpenBinlogIndex(indexPath string) (*BinlogSet, error) {
+	index, err := os.Open(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	defer index.Close()
+
+	set := &BinlogSet{
+		Files: []*BinlogFile{},
+	}
+
+	scanner := bufio.NewScanner(index)
+	for scanner.Scan() {
+		path := strings.TrimSpace(scanner.Text())
+		if path == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(indexPath), path)
+		}
+
+		set.Files = append(set.Files, &BinlogFile{
+			Name: filepath.Base(path),
+			Path: path,
+		})
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// Finds the files named basename.NNNNNN in dir (e.g. "mysql-bin"
+// for mysql-bin.000001), for when there is no index file
+func OpenBinlogDir(dir string, basename string) (*BinlogSet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	numbers := map[string]uint64{}
+	set := &BinlogSet{
+		Files: []*BinlogFile{},
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, basename+".") {
+			continue
+		}
+
+		number, err := strconv.ParseUint(strings.TrimPrefix(name, basename+"."), 10, 64)
+		if err != nil {
+			// mysql-bin.index and anything else that isn't a binlog
+			continue
+		}
+
+		numbers[name] = number
+		set.Files = append(set.Files, &BinlogFile{
+			Name: name,
+			Path: filepath.Join(dir, name),
+		})
+	}
+
+	// By number rather than name, the numbers outgrow their padding
+	// after mysql-bin.999999
+	sort.Slice(set.Files, func(i, j int) bool {
+		return numbers[set.Files[i].Name] < numbers[set.Files[j].Name]
+	})
+
+	return set, nil
+}
+
+func (set *BinlogSet) fileIndexOf(name string) int {
+	for i, file := range set.Files {
+		if file.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Orders positions across the files of the set: negative if a comes
+// before b, 0 if they are the same, positive if a comes after b
+func (set *BinlogSet) Compare(a, b Position) (int, error) {
+	aIndex := set.fileIndexOf(a.File)
+	if aIndex < 0 {
+		return 0, fmt.Errorf("%w: %v is not in the set", ErrInvalidPosition, a.File)
+	}
+
+	bIndex := set.fileIndexOf(b.File)
+	if bIndex < 0 {
+		return 0, fmt.Errorf("%w: %v is not in the set", ErrInvalidPosition, b.File)
+	}
+
+	if aIndex != bIndex {
+		return aIndex - bIndex, nil
+	}
+
+	switch {
+	case a.Offset < b.Offset:
+		return -1, nil
+	case a.Offset > b.Offset:
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+// Next carries on from position, which must be the start of an
+// event in one of the files
+func (set *BinlogSet) Seek(position Position) error {
+	i := set.fileIndexOf(position.File)
+	if i < 0 {
+		return fmt.Errorf("%w: %v is not in the set", ErrInvalidPosition, position.File)
+	}
+
+	file := set.Files[i]
+	b, err := OpenBinlogAt(filepath.Dir(file.Path), position)
+	if err != nil {
+		return err
+	}
+
+	set.Close()
+	set.current = b
+	set.fileIndex = i
+	set.resumeOffset = 0
+
+	return nil
+}
+
+func (set *BinlogSet) openCurrent() (*Binlog, error) {
+	file := set.Files[set.fileIndex]
+	if set.resumeOffset == 0 {
+		return OpenBinlog(file.Path)
+	}
+
+	return OpenBinlogAt(filepath.Dir(file.Path), Position{
+		File:   file.Name,
+		Offset: set.resumeOffset,
+	})
+}
+
+// Returns the events of every file in order, then io.EOF after the
+// last event of the last file. BinlogSet implements EventReader.
+//
+// A file is closed once its last event has been returned, after that
+// Data() fails with ErrEventReleased on any of its events that
+// haven't been decoded.
+func (set *BinlogSet) Next(ctx context.Context) (*Event, error) {
+	for set.fileIndex < len(set.Files) {
+		if set.current == nil {
+			b, err := set.openCurrent()
+			if err != nil {
+				return nil, err
+			}
+
+			set.current = b
+			set.resumeOffset = 0
+		}
+
+		// The set only moves forward, so there's no need to keep
+		// the events already returned (as Binlog.Events() does)
+		set.current.dropEvents(set.current.nextEventIndex)
+
+		event, err := set.current.Next(ctx)
+		if err != io.EOF || set.fileIndex == len(set.Files)-1 {
+			return event, err
+		}
+
+		set.Close()
+		set.fileIndex++
+		set.resumeOffset = 0
+	}
+
+	return nil, io.EOF
+}
+
+// Where the event after the last one Next returned starts, i.e.
+// where to pick up from with Seek
+func (set *BinlogSet) Position() Position {
+	if set.fileIndex >= len(set.Files) {
+		return Position{}
+	}
+
+	if set.current == nil {
+		offset := int64(MAGIC_BYTES_LENGTH)
+		if set.resumeOffset != 0 {
+			offset = set.resumeOffset
+		}
+
+		return Position{
+			File:   set.Files[set.fileIndex].Name,
+			Offset: offset,
+		}
+	}
+
+	return Position{
+		File:   set.Files[set.fileIndex].Name,
+		Offset: set.current.nextOffset(),
+	}
+}
+
+func (set *BinlogSet) Transactions() *TransactionIterator {
+	return NewTransactionIterator(set)
+}
+
+// Closes the file being read. Next opens it again and carries on
+// from Position(), events already returned from it are released (see
+// Next).
+func (set *BinlogSet) Close() error {
+	if set.current == nil {
+		return nil
+	}
+
+	set.resumeOffset = set.current.nextOffset()
+	err := set.current.close()
+	set.current = nil
+	return err
+}
@@ -0,0 +1,191 @@
+package binlog
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBinlogSetDir(t *testing.T) string {
+	dir := t.TempDir()
+
+	preamble := testBinlogPreamble()
+	fdeBody := preamble[MAGIC_BYTES_LENGTH+EVENT_HEADER_LENGTH : len(preamble)-BINLOG_CHECKSUM_LENGTH]
+
+	// Closed cleanly, rotated to the next file
+	closed := append([]byte{}, BINLOG_MAGIC[:]...)
+	closed = append(closed, serializeTestEvent(FORMAT_DESCRIPTION_EVENT, len(closed), 0, fdeBody)...)
+	closed = append(closed, testChecksummedBinlog()[len(preamble):]...)
+	writeTestFile(t, filepath.Join(dir, "mysql-bin.000001"), closed)
+
+	// Still being written to
+	active := append([]byte{}, preamble...)
+	active = append(active, serializeTestEvent(QUERY_EVENT, len(active), 0, testQueryEventBody("CREATE TABLE t (a int)"))...)
+	writeTestFile(t, filepath.Join(dir, "mysql-bin.000002"), active)
+
+	writeTestFile(t, filepath.Join(dir, "mysql-bin.index"), []byte("./mysql-bin.000001\n./mysql-bin.000002\n"))
+	return dir
+}
+
+func TestOpenBinlogDir(t *testing.T) {
+	dir := testBinlogSetDir(t)
+	writeTestFile(t, filepath.Join(dir, "mysql-bin.1000000"), testBinlogPreamble())
+	writeTestFile(t, filepath.Join(dir, "relay-bin.000001"), testBinlogPreamble())
+
+	set, err := OpenBinlogDir(dir, "mysql-bin")
+	assert.NoError(t, err)
+
+	names := []string{}
+	for _, file := range set.Files {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"mysql-bin.000001", "mysql-bin.000002", "mysql-bin.1000000"}, names)
+}
+
+func TestBinlogSetNext(t *testing.T) {
+	dir := testBinlogSetDir(t)
+
+	set, err := OpenBinlogIndex(filepath.Join(dir, "mysql-bin.index"))
+	assert.NoError(t, err)
+	defer set.Close()
+
+	assert.Len(t, set.Files, 2)
+	assert.Equal(t, filepath.Join(dir, "mysql-bin.000002"), set.Files[1].Path)
+
+	positions := []Position{}
+	for {
+		event, err := set.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+
+		positions = append(positions, event.FilePosition())
+	}
+
+	assert.Equal(t, []Position{
+		{File: "mysql-bin.000001", Offset: int64(len(testBinlogPreamble()))},
+		{File: "mysql-bin.000002", Offset: int64(len(testBinlogPreamble()))},
+	}, positions)
+
+	order, err := set.Compare(positions[0], positions[1])
+	assert.NoError(t, err)
+	assert.Less(t, order, 0)
+
+	_, err = set.Compare(positions[0], Position{File: "mysql-bin.000009", Offset: 4})
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+
+	// Start over from the second file
+	assert.NoError(t, set.Seek(positions[1]))
+
+	event, err := set.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, QUERY_EVENT, event.Type())
+	assert.Equal(t, positions[1], event.FilePosition())
+}
+
+func TestBinlogFileMetadata(t *testing.T) {
+	dir := testBinlogSetDir(t)
+
+	set, err := OpenBinlogDir(dir, "mysql-bin")
+	assert.NoError(t, err)
+
+	rotated, err := set.Files[0].Metadata()
+	assert.NoError(t, err)
+	assert.True(t, rotated.Closed)
+	assert.Nil(t, rotated.PreviousGtids)
+
+	stat, err := os.Stat(set.Files[0].Path)
+	assert.NoError(t, err)
+	assert.Equal(t, stat.Size(), rotated.Size)
+
+	active, err := set.Files[1].Metadata()
+	assert.NoError(t, err)
+	assert.False(t, active.Closed)
+}
+
+func TestBinlogSetNextMemory(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"mysql-bin.000001", "mysql-bin.000002"} {
+		log := testBinlogPreamble()
+		for i := 0; i < 50; i++ {
+			log = append(log, serializeTestEvent(QUERY_EVENT, len(log), 0, testQueryEventBody("CREATE TABLE t (a int)"))...)
+		}
+
+		writeTestFile(t, filepath.Join(dir, name), log)
+	}
+
+	set, err := OpenBinlogDir(dir, "mysql-bin")
+	assert.NoError(t, err)
+	defer set.Close()
+
+	count := 0
+	for {
+		event, err := set.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		count++
+
+		assert.LessOrEqual(t, len(set.current.events), 1)
+
+		// Still decodable after the events before it were dropped
+		_, err = event.Data()
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, 100, count)
+}
+
+func TestBinlogSetClose(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"mysql-bin.000001", "mysql-bin.000002"} {
+		log := testBinlogPreamble()
+		for i := 0; i < 3; i++ {
+			log = append(log, serializeTestEvent(ROWS_QUERY_EVENT, len(log), 0, []byte("\x18INSERT INTO t VALUES (1)"))...)
+		}
+
+		writeTestFile(t, filepath.Join(dir, name), log)
+	}
+
+	set, err := OpenBinlogDir(dir, "mysql-bin")
+	assert.NoError(t, err)
+	defer set.Close()
+
+	first, err := set.Next(context.Background())
+	assert.NoError(t, err)
+
+	position := set.Position()
+	assert.NoError(t, set.Close())
+	assert.Equal(t, position, set.Position())
+
+	// Picks up where it left off rather than replaying the file
+	event, err := set.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, position, event.FilePosition())
+
+	_, err = first.Data()
+	assert.ErrorIs(t, err, ErrEventReleased)
+
+	last, err := set.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "mysql-bin.000001", last.FilePosition().File)
+
+	event, err = set.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "mysql-bin.000002", event.FilePosition().File)
+
+	// The first file was closed when the set moved on
+	_, err = last.Data()
+	assert.ErrorIs(t, err, ErrEventReleased)
+
+	_, err = event.Data()
+	assert.NoError(t, err)
+}
@@ -1,84 +1,299 @@
 package binlog
 
 import (
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+TAILING A BINLOG
+================
+
+MySQL appends to the binlog it is writing and flushes whenever it
+likes, so the end of the file can be in the middle of an event. The
+tailer reads with ReadAt from the position after the last whole
+event it returned, and only moves that position once a whole event
+is there. A read that has to wait (or is cancelled) loses nothing.
+
+While waiting, the tailer wakes up when the file changes (inotify on
+Linux) or every TAIL_POLL_INTERVAL otherwise. When it runs out of
+data it also checks what happened to the file:
+
+ErrFileTruncated = the file is shorter than what was already read
+ErrFileReplaced  = the path is now a different file (RESET MASTER)
+ErrFileDeleted   = the path is gone (PURGE BINARY LOGS)
+
+Anything left in the old file is still read before these are
+returned (except truncation, where it's gone). They come wrapped in
+a *TailError with the path and position.
 
-	"github.com/aybabtme/tailf"
+*/
+
+// How often to check for new data when the file can't be watched
+const TAIL_POLL_INTERVAL = 100 * time.Millisecond
+
+// Checks are still made this often when the file is watched, in
+// case a change wasn't reported
+const TAIL_WATCH_INTERVAL = time.Second
+
+var (
+	ErrFileTruncated = errors.New("binlog file was truncated")
+	ErrFileReplaced  = errors.New("binlog file was replaced")
+	ErrFileDeleted   = errors.New("binlog file was deleted")
+	ErrTailerClosed  = errors.New("tailer is closed")
+
+	// Not enough data yet, used internally
+	errIncompleteEvent = errors.New("incomplete event")
 )
 
+type TailError struct {
+	Path     string
+	Position int64
+	Err      error
+}
+
+func (e *TailError) Error() string {
+	return fmt.Sprintf("tailing %v at position %v: %v", e.Path, e.Position, e.Err)
+}
+
+func (e *TailError) Unwrap() error {
+	return e.Err
+}
+
+// Wakes up a waiting tailer when the file may have changed
+type fileWatcher interface {
+	Changed() <-chan struct{}
+	Close() error
+}
+
 type BinlogTailer struct {
-	tailReader   io.ReadCloser
-	lastPosition int
+	path     string
+	file     *os.File
+	position int64
+	watcher  fileWatcher
+
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
+// Opens a binlog to follow. The magic bytes are checked now if they
+// have been written, or before the first event otherwise.
 func Tail(filepath string) (*BinlogTailer, error) {
-	var err error
-	tailer := new(BinlogTailer)
-
-	tailer.tailReader, err = tailf.Follow(filepath, true)
+	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, err
 	}
 
-	tailer.lastPosition = 4
+	tailer := &BinlogTailer{
+		path:   filepath,
+		file:   file,
+		closed: make(chan struct{}),
+	}
+
+	// Without inotify we poll
+	tailer.watcher, _ = watchFile(filepath)
 
-	return tailer, tailer.readMagicBytes()
+	if err = tailer.readMagicBytes(); err != nil && err != errIncompleteEvent {
+		tailer.Close()
+		return nil, err
+	}
+
+	return tailer, nil
 }
 
 func (tailer *BinlogTailer) readMagicBytes() error {
-	magicBytes := make([]byte, MAGIC_BYTES_LENGTH)
-	n, err := tailer.tailReader.Read(magicBytes)
+	if tailer.position != 0 {
+		return nil
+	}
+
+	magic := make([]byte, MAGIC_BYTES_LENGTH)
+	n, err := tailer.file.ReadAt(magic, 0)
+	if n < MAGIC_BYTES_LENGTH {
+		if err == io.EOF {
+			return errIncompleteEvent
+		}
 
-	if n != MAGIC_BYTES_LENGTH {
-		return fmt.Errorf("Failed to read magic bytes: read length mismatch")
+		return tailer.fileError(err)
 	}
 
-	if err != nil {
-		return fmt.Errorf("Failed to read magic bytes: %v", err.Error())
+	if !checkBinlogMagic(magic) {
+		return tailer.error(ErrInvalidMagic)
 	}
 
-	/*
-		TODO: can't compare []byte to [4]byte
+	tailer.position = int64(MAGIC_BYTES_LENGTH)
+	return nil
+}
+
+// Path of the file being followed
+func (tailer *BinlogTailer) Path() string {
+	return tailer.path
+}
+
+// Position in the file after the last event returned
+func (tailer *BinlogTailer) Position() int64 {
+	return tailer.position
+}
+
+// Stops the tailer, a ReadSerializedEvent waiting in another
+// goroutine returns ErrTailerClosed
+func (tailer *BinlogTailer) Close() error {
+	err := ErrTailerClosed
 
-		if magicBytes != BINLOG_MAGIC {
-			return fmt.Errorf("Failed to read magic bytes: got %v, expected %v", magicBytes, BINLOG_MAGIC)
+	tailer.closeOnce.Do(func() {
+		close(tailer.closed)
+
+		if tailer.watcher != nil {
+			tailer.watcher.Close()
 		}
-	*/
 
-	return nil
+		err = tailer.file.Close()
+	})
+
+	return err
 }
 
-func (tailer *BinlogTailer) Close() {
-	tailer.tailReader.Close()
+// For errors from the file, which are ErrTailerClosed if Close
+// closed it under a read
+func (tailer *BinlogTailer) fileError(err error) error {
+	select {
+	case <-tailer.closed:
+		return tailer.error(ErrTailerClosed)
+	default:
+	}
+
+	if errors.Is(err, os.ErrClosed) {
+		return tailer.error(ErrTailerClosed)
+	}
+
+	return tailer.error(err)
 }
 
-// recursively read remaining bytes until a certain length of bytes are read
-func (tailer *BinlogTailer) readByLength(preloadedBytes []byte, length int) []byte {
-	remainingBytes := make([]byte, length-len(preloadedBytes))
-	n, err := tailer.tailReader.Read(remainingBytes)
+func (tailer *BinlogTailer) error(err error) error {
+	return &TailError{
+		Path:     tailer.path,
+		Position: tailer.position,
+		Err:      err,
+	}
+}
+
+// Waits for the next whole event (header included) until ctx is
+// done. Calling it again after an error carries on from the same
+// position.
+func (tailer *BinlogTailer) ReadSerializedEvent(ctx context.Context) ([]byte, error) {
+	for {
+		select {
+		case <-tailer.closed:
+			return nil, tailer.error(ErrTailerClosed)
+		default:
+		}
+
+		event, err := tailer.readEvent()
+		if err != errIncompleteEvent {
+			return event, err
+		}
+
+		if err = tailer.checkFile(); err != nil {
+			return nil, err
+		}
+
+		if err = tailer.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Returns errIncompleteEvent if the whole event isn't there yet
+func (tailer *BinlogTailer) readEvent() ([]byte, error) {
+	if err := tailer.readMagicBytes(); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, EVENT_HEADER_LENGTH)
+	if err := tailer.readAt(header, tailer.position); err != nil {
+		return nil, err
+	}
+
+	length := int(binary.LittleEndian.Uint32(header[EVENT_LEN_OFFSET:EVENT_NEXT_OFFSET]))
+	if length < EVENT_HEADER_LENGTH {
+		return nil, tailer.error(fmt.Errorf("%w: event length %v is shorter than its header", ErrMalformedEvent, length))
+	}
+
+	event := make([]byte, length)
+	if err := tailer.readAt(event, tailer.position); err != nil {
+		return nil, err
+	}
 
+	tailer.position += int64(length)
+	return event, nil
+}
+
+func (tailer *BinlogTailer) readAt(b []byte, position int64) error {
+	n, err := tailer.file.ReadAt(b, position)
+	if n == len(b) {
+		return nil
+	}
+
+	if err == io.EOF {
+		return errIncompleteEvent
+	}
+
+	return tailer.fileError(err)
+}
+
+// Called once the file has run out of data
+func (tailer *BinlogTailer) checkFile() error {
+	stat, err := tailer.file.Stat()
 	if err != nil {
-		panic(fmt.Errorf("Failed to read serialized event header: %v", err))
+		return tailer.fileError(err)
 	}
 
-	if n != len(remainingBytes) {
-		return tailer.readByLength(append(preloadedBytes, remainingBytes[:n]...), length)
+	if stat.Size() < tailer.position {
+		return tailer.error(ErrFileTruncated)
 	}
 
-	return append(preloadedBytes, remainingBytes...)
+	pathStat, err := os.Stat(tailer.path)
+	if os.IsNotExist(err) {
+		return tailer.error(ErrFileDeleted)
+	}
+	if err != nil {
+		return tailer.error(err)
+	}
+
+	if !os.SameFile(stat, pathStat) {
+		return tailer.error(ErrFileReplaced)
+	}
+
+	return nil
 }
 
-// errors are currently non-recoverable
-func (tailer *BinlogTailer) ReadSerializedEvent() []byte {
-	headerBytes := tailer.readByLength([]byte{}, EVENT_HEADER_LENGTH)
+func (tailer *BinlogTailer) wait(ctx context.Context) error {
+	interval := TAIL_POLL_INTERVAL
+	var changed <-chan struct{}
+
+	if tailer.watcher != nil {
+		interval = TAIL_WATCH_INTERVAL
+		changed = tailer.watcher.Changed()
+	}
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
 
-	nextPosition := int(binary.LittleEndian.Uint32(headerBytes[EVENT_NEXT_OFFSET:EVENT_FLAGS_OFFSET]))
-	dataLength := nextPosition - tailer.lastPosition - len(headerBytes)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
 
-	dataBytes := tailer.readByLength([]byte{}, dataLength)
+	case <-tailer.closed:
+		return tailer.error(ErrTailerClosed)
 
-	tailer.lastPosition = nextPosition
-	return append(headerBytes, dataBytes...)
+	case <-changed:
+	case <-timer.C:
+	}
+
+	return nil
 }
@@ -0,0 +1,69 @@
+//go:build linux
+
+package binlog
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+type inotifyWatcher struct {
+	file    *os.File
+	changed chan struct{}
+}
+
+// Watches the file for writes, and its directory for a new file
+// taking its place
+func watchFile(path string) (fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	watches := map[string]uint32{
+		path:               syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF,
+		filepath.Dir(path): syscall.IN_CREATE | syscall.IN_MOVED_TO,
+	}
+
+	for watchPath, mask := range watches {
+		if _, err = syscall.InotifyAddWatch(fd, watchPath, mask); err != nil {
+			syscall.Close(fd)
+			return nil, err
+		}
+	}
+
+	// Non-blocking, so reads go through the runtime poller and
+	// Close wakes up the goroutine below
+	w := &inotifyWatcher{
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		changed: make(chan struct{}, 1),
+	}
+
+	go w.run()
+	return w, nil
+}
+
+func (w *inotifyWatcher) run() {
+	buf := make([]byte, 4096)
+
+	for {
+		// What changed doesn't matter, the tailer looks for itself
+		if _, err := w.file.Read(buf); err != nil {
+			return
+		}
+
+		select {
+		case w.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *inotifyWatcher) Changed() <-chan struct{} {
+	return w.changed
+}
+
+func (w *inotifyWatcher) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package binlog
+
+import (
+	"errors"
+)
+
+// Other platforms poll every TAIL_POLL_INTERVAL
+func watchFile(path string) (fileWatcher, error) {
+	return nil, errors.New("file watching is not supported on this platform")
+}
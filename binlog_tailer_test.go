@@ -0,0 +1,168 @@
+package binlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, path string, b []byte) {
+	assert.NoError(t, os.WriteFile(path, b, 0644))
+}
+
+func appendTestFile(t *testing.T, path string, b []byte) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.Write(b)
+	assert.NoError(t, err)
+}
+
+func TestTailInvalidMagic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	writeTestFile(t, path, []byte("not a binlog"))
+
+	_, err := Tail(path)
+	assert.ErrorIs(t, err, ErrInvalidMagic)
+}
+
+func TestTailerPartialWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	log := testChecksummedBinlog()
+	split := len(testBinlogPreamble()) + 10
+
+	writeTestFile(t, path, log[:split])
+
+	tailer, err := Tail(path)
+	assert.NoError(t, err)
+	defer tailer.Close()
+
+	_, err = tailer.ReadSerializedEvent(context.Background())
+	assert.NoError(t, err)
+
+	// The rotate event is only partly written
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = tailer.ReadSerializedEvent(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		appendTestFile(t, path, log[split:])
+	}()
+
+	event, err := tailer.ReadSerializedEvent(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, log[len(testBinlogPreamble()):], event)
+	assert.Equal(t, int64(len(log)), tailer.Position())
+}
+
+func TestTailerTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	writeTestFile(t, path, testChecksummedBinlog())
+
+	tailer, err := Tail(path)
+	assert.NoError(t, err)
+	defer tailer.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err = tailer.ReadSerializedEvent(context.Background())
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, os.Truncate(path, int64(MAGIC_BYTES_LENGTH)))
+
+	_, err = tailer.ReadSerializedEvent(context.Background())
+	assert.ErrorIs(t, err, ErrFileTruncated)
+}
+
+func TestTailerReplaced(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mysql-bin.000001")
+	writeTestFile(t, path, testChecksummedBinlog())
+
+	tailer, err := Tail(path)
+	assert.NoError(t, err)
+	defer tailer.Close()
+
+	replacement := filepath.Join(dir, "replacement")
+	writeTestFile(t, replacement, testBinlogPreamble())
+	assert.NoError(t, os.Rename(replacement, path))
+
+	// What was left in the old file is still read
+	for i := 0; i < 2; i++ {
+		_, err = tailer.ReadSerializedEvent(context.Background())
+		assert.NoError(t, err)
+	}
+
+	_, err = tailer.ReadSerializedEvent(context.Background())
+	assert.ErrorIs(t, err, ErrFileReplaced)
+
+	var tailErr *TailError
+	assert.ErrorAs(t, err, &tailErr)
+	assert.Equal(t, path, tailErr.Path)
+}
+
+func TestTailerClosed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	writeTestFile(t, path, testBinlogPreamble())
+
+	tailer, err := Tail(path)
+	assert.NoError(t, err)
+
+	_, err = tailer.ReadSerializedEvent(context.Background())
+	assert.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		tailer.Close()
+	}()
+
+	_, err = tailer.ReadSerializedEvent(context.Background())
+	assert.ErrorIs(t, err, ErrTailerClosed)
+}
+
+func TestTailerConcurrentClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+
+	log := testBinlogPreamble()
+	for i := 0; i < 1000; i++ {
+		log = append(log, serializeTestEvent(QUERY_EVENT, len(log), 0, testQueryEventBody("CREATE TABLE t (a int)"))...)
+	}
+	writeTestFile(t, path, log)
+
+	for i := 0; i < 20; i++ {
+		tailer, err := Tail(path)
+		assert.NoError(t, err)
+
+		go tailer.Close()
+
+		// Whether Close lands between reads or in the middle of one
+		for err == nil {
+			_, err = tailer.ReadSerializedEvent(context.Background())
+		}
+
+		assert.ErrorIs(t, err, ErrTailerClosed)
+	}
+}
+
+func TestTailerFileClosedDuringRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	writeTestFile(t, path, testChecksummedBinlog())
+
+	tailer, err := Tail(path)
+	assert.NoError(t, err)
+	defer tailer.Close()
+
+	// What a read in flight sees when Close gets to the file first
+	tailer.file.Close()
+
+	_, err = tailer.ReadSerializedEvent(context.Background())
+	assert.ErrorIs(t, err, ErrTailerClosed)
+}
@@ -4,10 +4,13 @@ package bitset
 // For info on basic bitwise operations: http://stackoverflow.com/a/47990/3830940
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"math"
 )
 
+var ErrLengthMismatch = errors.New("not enough bytes for the bitset")
+
 // Keeping the uint64 from the original for now
 // this may be changed later
 type Bitset []uint64
@@ -23,20 +26,31 @@ func Make(maxSize uint) Bitset {
 	return s
 }
 
-func MakeFromByteArray(bytes []byte, maxSize uint) Bitset {
+// Bit j of bytes[i] is bit i*8+j of the set, bits past maxSize are
+// ignored
+func MakeFromByteArray(bytes []byte, maxSize uint) (Bitset, error) {
 	if int((maxSize+7)/8) > len(bytes) {
-		log.Fatal("Bitset maxSize and []byte length mismatch")
+		return nil, fmt.Errorf("%w: %v bytes for %v bits", ErrLengthMismatch, len(bytes), maxSize)
 	}
 
 	bitset := Make(maxSize)
 
-	for i := uint(0); i < uint(len(bytes)); i++ {
-		block := bytes[i]
+	for i := uint(0); i < maxSize; i++ {
+		if bytes[i/8]&(1<<(i%8)) != 0 {
+			bitset.SetBit(i)
+		}
+	}
+
+	return bitset, nil
+}
+
+// Bit i of the set is bit i of value (counting from the least significant)
+func MakeFromUint64(value uint64, maxSize uint) Bitset {
+	bitset := Make(maxSize)
 
-		for j := uint(0); j < 8; j++ {
-			if ((block) & (1 << (j))) > 0 {
-				bitset.SetBit((i * 8) + j)
-			}
+	for i := uint(0); i < maxSize && i < 64; i++ {
+		if value&(1<<i) != 0 {
+			bitset.SetBit(i)
 		}
 	}
 
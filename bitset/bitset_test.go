@@ -37,3 +37,25 @@ func TestBitsetClear(t *testing.T) {
 	assert.False(t, set.Bit(2))
 	assert.False(t, set.Bit(5))
 }
+
+func TestBitsetMakeFromUint64(t *testing.T) {
+	set := MakeFromUint64(0x8000000000000005, 64)
+
+	assert.True(t, set.Bit(0))
+	assert.False(t, set.Bit(1))
+	assert.True(t, set.Bit(2))
+	assert.True(t, set.Bit(63))
+	assert.Equal(t, 3, set.Count())
+}
+
+func TestBitsetMakeFromByteArray(t *testing.T) {
+	set, err := MakeFromByteArray([]byte{0x05, 0x81}, 12)
+	assert.NoError(t, err)
+	assert.True(t, set.Bit(0))
+	assert.True(t, set.Bit(2))
+	assert.True(t, set.Bit(8))
+	assert.Equal(t, 3, set.Count()) // bit 15 is past maxSize
+
+	_, err = MakeFromByteArray([]byte{0xff}, 9)
+	assert.ErrorIs(t, err, ErrLengthMismatch)
+}
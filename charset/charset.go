@@ -0,0 +1,120 @@
+package charset
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+CHARACTER SETS
+==============
+
+Binlogs identify a column's character set by one of its collation
+ids (see information_schema.COLLATIONS). Only the character sets
+below can be decoded to UTF-8; everything else is reported as
+unsupported rather than guessed at.
+
+binary  = raw bytes, no decoding
+ascii   = decoded as latin1
+latin1  = MySQL's latin1, which is really cp1252
+cp1252  = Windows-1252
+utf8    = utf8mb3, validated
+utf8mb4 = validated
+
+*/
+
+const (
+	BINARY  = "binary"
+	ASCII   = "ascii"
+	LATIN1  = "latin1"
+	CP1252  = "cp1252"
+	UTF8    = "utf8"
+	UTF8MB4 = "utf8mb4"
+)
+
+var ErrUnsupportedCharset = errors.New("unsupported character set")
+var ErrInvalidEncoding = errors.New("invalid encoding")
+
+// Character set name for a collation id, "" if unknown
+func CollationCharset(collationId uint64) string {
+	switch {
+	case collationId == 63:
+		return BINARY
+
+	case collationId == 11, collationId == 65:
+		return ASCII
+
+	case collationId == 5, collationId == 8, collationId == 15, collationId == 31,
+		collationId >= 47 && collationId <= 49, collationId == 94:
+		return LATIN1
+
+	case collationId == 33, collationId == 76, collationId == 83,
+		collationId >= 192 && collationId <= 215, collationId == 223:
+		return UTF8
+
+	case collationId == 45, collationId == 46,
+		collationId >= 224 && collationId <= 247, collationId >= 255 && collationId <= 323:
+		return UTF8MB4
+	}
+
+	return ""
+}
+
+// Name as used by Decode, e.g. "UTF8MB3" is "utf8"
+func normalize(charset string) string {
+	charset = strings.ToLower(charset)
+
+	if charset == "utf8mb3" {
+		return UTF8
+	}
+
+	return charset
+}
+
+func IsBinary(charset string) bool {
+	return normalize(charset) == BINARY
+}
+
+// Converts text in charset to a UTF-8 string. Binary values are
+// returned as they are.
+func Decode(charset string, b []byte) (string, error) {
+	switch normalize(charset) {
+	case BINARY:
+		return string(b), nil
+
+	case UTF8, UTF8MB4:
+		if !utf8.Valid(b) {
+			return "", fmt.Errorf("%w: not %v", ErrInvalidEncoding, charset)
+		}
+
+		return string(b), nil
+
+	case ASCII, LATIN1, CP1252:
+		return decodeCp1252(b), nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedCharset, charset)
+}
+
+// 0x80-0x9f in cp1252. The bytes Windows leaves undefined (0x81,
+// 0x8d, 0x8f, 0x90, 0x9d) map to the C1 controls like MySQL does.
+var cp1252High = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+func decodeCp1252(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		if c >= 0x80 && c <= 0x9f {
+			runes[i] = cp1252High[c-0x80]
+		} else {
+			// Everything else lines up with Unicode
+			runes[i] = rune(c)
+		}
+	}
+
+	return string(runes)
+}
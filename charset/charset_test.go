@@ -0,0 +1,45 @@
+package charset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollationCharset(t *testing.T) {
+	assert.Equal(t, BINARY, CollationCharset(63))
+	assert.Equal(t, LATIN1, CollationCharset(8))
+	assert.Equal(t, UTF8, CollationCharset(33))
+	assert.Equal(t, UTF8MB4, CollationCharset(45))
+	assert.Equal(t, UTF8MB4, CollationCharset(255))
+	assert.Equal(t, "", CollationCharset(1))
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		charset  string
+		b        []byte
+		expected string
+	}{
+		{LATIN1, []byte{'c', 'a', 'f', 0xe9}, "café"},
+		{CP1252, []byte{0x80, ' ', 0x93, 'q', 0x94, 0x81}, "€ “q”\u0081"},
+		{UTF8, []byte("café"), "café"},
+		{"utf8mb3", []byte("café"), "café"},
+		{UTF8MB4, []byte("😀"), "😀"},
+		{BINARY, []byte{0xff, 0x00}, "\xff\x00"},
+	}
+
+	for _, c := range cases {
+		s, err := Decode(c.charset, c.b)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, s)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	_, err := Decode(UTF8MB4, []byte{0xff})
+	assert.ErrorIs(t, err, ErrInvalidEncoding)
+
+	_, err = Decode("sjis", []byte{'a'})
+	assert.ErrorIs(t, err, ErrUnsupportedCharset)
+}
@@ -0,0 +1,177 @@
+package binlog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+
+	"github.com/granicus/mysql-binlog-go/deserialization"
+)
+
+/*
+EVENT CHECKSUMS
+===============
+
+Since MySQL 5.6.1, a FORMAT_DESCRIPTION_EVENT ends with the checksum
+algorithm used for every event in the file:
+
+1 byte  = checksum algorithm
+4 bytes = checksum of the FORMAT_DESCRIPTION_EVENT itself
+
+When the algorithm is CRC32, every event (including the format
+description) has a trailing 4 byte little endian CRC32 (IEEE)
+of the whole event, header included. The format description
+checksum is computed with the LOG_EVENT_BINLOG_IN_USE_F flag
+cleared, since that flag is flipped when the file is closed.
+
+*/
+
+type ChecksumAlgorithm byte
+
+const (
+	BINLOG_CHECKSUM_ALG_OFF   ChecksumAlgorithm = 0
+	BINLOG_CHECKSUM_ALG_CRC32 ChecksumAlgorithm = 1
+	BINLOG_CHECKSUM_ALG_UNDEF ChecksumAlgorithm = 255
+)
+
+const BINLOG_CHECKSUM_LENGTH int = 4
+
+// Header flag set while the file is still being written to
+const LOG_EVENT_BINLOG_IN_USE_F uint16 = 0x1
+
+func (alg ChecksumAlgorithm) String() string {
+	switch alg {
+	case BINLOG_CHECKSUM_ALG_OFF:
+		return "OFF"
+	case BINLOG_CHECKSUM_ALG_CRC32:
+		return "CRC32"
+	case BINLOG_CHECKSUM_ALG_UNDEF:
+		return "UNDEF"
+	}
+
+	return "invalid"
+}
+
+// Number of trailing bytes the algorithm adds to every event
+func (alg ChecksumAlgorithm) Length() int {
+	if alg == BINLOG_CHECKSUM_ALG_CRC32 {
+		return BINLOG_CHECKSUM_LENGTH
+	}
+
+	return 0
+}
+
+type ChecksumMismatchError struct {
+	Position int64
+	Type     MysqlBinlogEventType
+	Expected uint32
+	Actual   uint32
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch in %v at position %v: expected %08x, got %08x",
+		e.Type, e.Position, e.Expected, e.Actual)
+}
+
+// Whether a server version string (e.g. "5.6.27-log") is at least
+// major.minor.patch
+func serverVersionAtLeast(version string, major, minor, patch int) bool {
+	if i := strings.IndexFunc(version, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); i >= 0 {
+		version = version[:i]
+	}
+
+	parts := strings.Split(version, ".")
+	wanted := []int{major, minor, patch}
+
+	for i, w := range wanted {
+		n := 0
+		if i < len(parts) {
+			n, _ = strconv.Atoi(parts[i])
+		}
+
+		if n != w {
+			return n > w
+		}
+	}
+
+	return true
+}
+
+// Format description events written by servers that know about
+// checksums carry the algorithm byte, whether or not it is OFF
+func hasChecksumAlgorithm(serverVersion string) bool {
+	return serverVersionAtLeast(serverVersion, 5, 6, 1)
+}
+
+// Turns on CRC32 verification when decoding event data. Failures
+// are returned from Event.Data() as an *EventError wrapping a
+// *ChecksumMismatchError.
+func (b *Binlog) SetVerifyChecksums(verify bool) {
+	b.verifyChecksums = verify
+}
+
+func (b *Binlog) verifyEventChecksum(startPosition int64, header *EventHeader) error {
+	if err := b.SetPosition(startPosition); err != nil {
+		return err
+	}
+
+	eventBytes, err := deserialization.ReadBytes(b.reader, int(header.Length))
+	if err != nil {
+		return err
+	}
+
+	alg := b.formatDescription.ChecksumAlgorithm
+	if header.Type == FORMAT_DESCRIPTION_EVENT {
+		// This event decides the algorithm for itself
+		alg = formatDescriptionChecksumAlgorithm(eventBytes)
+	}
+
+	if alg != BINLOG_CHECKSUM_ALG_CRC32 {
+		return nil
+	}
+
+	if len(eventBytes) < EVENT_HEADER_LENGTH+BINLOG_CHECKSUM_LENGTH {
+		return ErrTruncatedEvent
+	}
+
+	split := len(eventBytes) - BINLOG_CHECKSUM_LENGTH
+	expected := binary.LittleEndian.Uint32(eventBytes[split:])
+
+	if header.Type == FORMAT_DESCRIPTION_EVENT {
+		flags := binary.LittleEndian.Uint16(eventBytes[EVENT_FLAGS_OFFSET:])
+		binary.LittleEndian.PutUint16(eventBytes[EVENT_FLAGS_OFFSET:], flags&^LOG_EVENT_BINLOG_IN_USE_F)
+	}
+
+	actual := crc32.ChecksumIEEE(eventBytes[:split])
+	if actual != expected {
+		return &ChecksumMismatchError{
+			Position: startPosition,
+			Type:     header.Type,
+			Expected: expected,
+			Actual:   actual,
+		}
+	}
+
+	return nil
+}
+
+// Pulls the checksum algorithm out of a whole serialized
+// FORMAT_DESCRIPTION_EVENT (header included)
+func formatDescriptionChecksumAlgorithm(eventBytes []byte) ChecksumAlgorithm {
+	versionStart := EVENT_HEADER_LENGTH + 2
+	versionEnd := versionStart + SERVER_VERSION_LENGTH
+	algPosition := len(eventBytes) - BINLOG_CHECKSUM_LENGTH - 1
+
+	if algPosition < versionEnd {
+		return BINLOG_CHECKSUM_ALG_OFF
+	}
+
+	serverVersion := strings.TrimRight(string(eventBytes[versionStart:versionEnd]), "\x00")
+	if !hasChecksumAlgorithm(serverVersion) {
+		return BINLOG_CHECKSUM_ALG_OFF
+	}
+
+	return ChecksumAlgorithm(eventBytes[algPosition])
+}
@@ -0,0 +1,82 @@
+package binlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Serializes a v4 event with a CRC32 checksum, positioned at position
+func serializeTestEvent(eventType MysqlBinlogEventType, position int, flags uint16, body []byte) []byte {
+	length := EVENT_HEADER_LENGTH + len(body) + BINLOG_CHECKSUM_LENGTH
+
+	event := make([]byte, EVENT_HEADER_LENGTH)
+	event[EVENT_TYPE_OFFSET] = byte(eventType)
+	binary.LittleEndian.PutUint32(event[EVENT_LEN_OFFSET:], uint32(length))
+	binary.LittleEndian.PutUint32(event[EVENT_NEXT_OFFSET:], uint32(position+length))
+	event = append(event, body...)
+
+	checksumBytes := append([]byte{}, event...)
+	binary.LittleEndian.PutUint16(checksumBytes[EVENT_FLAGS_OFFSET:], flags&^LOG_EVENT_BINLOG_IN_USE_F)
+	binary.LittleEndian.PutUint16(event[EVENT_FLAGS_OFFSET:], flags)
+
+	checksum := make([]byte, BINLOG_CHECKSUM_LENGTH)
+	binary.LittleEndian.PutUint32(checksum, crc32.ChecksumIEEE(checksumBytes))
+
+	return append(event, checksum...)
+}
+
+// Magic bytes and a CRC32 FORMAT_DESCRIPTION_EVENT
+func testBinlogPreamble() []byte {
+	fdeBody := []byte{0x04, 0x00}
+	fdeBody = append(fdeBody, []byte("5.6.27-log")...)
+	fdeBody = append(fdeBody, make([]byte, SERVER_VERSION_LENGTH-len("5.6.27-log"))...)
+	fdeBody = append(fdeBody, 0x00, 0x00, 0x00, 0x00, byte(EVENT_HEADER_LENGTH))
+	fdeBody = append(fdeBody, defaultFormatDescription().PostHeaderLengths...)
+	fdeBody = append(fdeBody, byte(BINLOG_CHECKSUM_ALG_CRC32))
+
+	log := append([]byte{}, BINLOG_MAGIC[:]...)
+	return append(log, serializeTestEvent(FORMAT_DESCRIPTION_EVENT, len(log), LOG_EVENT_BINLOG_IN_USE_F, fdeBody)...)
+}
+
+func testChecksummedBinlog() []byte {
+	log := testBinlogPreamble()
+
+	rotateBody := []byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	rotateBody = append(rotateBody, []byte("mysql-bin.000002")...)
+
+	return append(log, serializeTestEvent(ROTATE_EVENT, len(log), 0, rotateBody)...)
+}
+
+func TestChecksumStrippedFromEventData(t *testing.T) {
+	b, err := NewBinlog(bytes.NewReader(testChecksummedBinlog()))
+	assert.NoError(t, err)
+	b.SetVerifyChecksums(true)
+
+	assert.Equal(t, BINLOG_CHECKSUM_ALG_CRC32, b.FormatDescription().ChecksumAlgorithm)
+	assert.Len(t, b.Events(), 1)
+
+	data, err := b.Events()[0].Data()
+	assert.NoError(t, err)
+	assert.Equal(t, "mysql-bin.000002", data.(*RotateEvent).NextFile)
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	log := testChecksummedBinlog()
+	log[len(log)-BINLOG_CHECKSUM_LENGTH-1] ^= 0xff
+
+	b, err := NewBinlog(bytes.NewReader(log))
+	assert.NoError(t, err)
+	b.SetVerifyChecksums(true)
+
+	_, err = b.Events()[0].Data()
+
+	var mismatch *ChecksumMismatchError
+	assert.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, ROTATE_EVENT, mismatch.Type)
+	assert.Equal(t, b.Events()[0].Position(), mismatch.Position)
+}
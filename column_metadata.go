@@ -2,9 +2,8 @@ package binlog
 
 import (
 	"encoding/binary"
-	"errors"
+	"fmt"
 	"io"
-	"log"
 
 	"github.com/granicus/mysql-binlog-go/deserialization"
 )
@@ -29,9 +28,12 @@ VARCHAR_METADATA
 
 STRING_METADATA
 [2]byte: [byte realtype (mysql var type), uint8 packsize]
+(CHAR columns longer than 255 bytes keep bits 8 and 9 of their
+max length in the 0x30 bits of the real type, inverted)
 
 BITSET_METADATA
-[2]byte: [uint8 number of bits, uint8 packsize]
+[2]byte: [uint8 bits past the last whole byte, uint8 whole bytes]
+(BIT(10) is [2, 1] and takes up 2 bytes)
 
 NEW_DECIMAL_METADATA
 [2]byte: [uint8 precision, uint8 number of decimals]
@@ -47,8 +49,23 @@ const (
 	TIME_V2_METADATA
 )
 
-func fatalMetadataLengthMismatch() {
-	fatalErr(errors.New("Mismatch of metadata length!"))
+func (mt MetadataType) String() string {
+	switch mt {
+	case PACK_SIZE_METADATA:
+		return "PACK_SIZE_METADATA"
+	case VARCHAR_METADATA:
+		return "VARCHAR_METADATA"
+	case STRING_METADATA:
+		return "STRING_METADATA"
+	case BITSET_METADATA:
+		return "BITSET_METADATA"
+	case NEW_DECIMAL_METADATA:
+		return "NEW_DECIMAL_METADATA"
+	case TIME_V2_METADATA:
+		return "TIME_V2_METADATA"
+	}
+
+	return "invalid"
 }
 
 type ColumnMetadata struct {
@@ -56,155 +73,112 @@ type ColumnMetadata struct {
 	metaType MetadataType
 }
 
-func DeserializeColomnMetadata(r io.Reader, colType MysqlType) *ColumnMetadata {
+func newColumnMetadata(r io.Reader, length int, metaType MetadataType) (*ColumnMetadata, error) {
+	data, err := deserialization.ReadBytes(r, length)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ColumnMetadata{
+		data:     data,
+		metaType: metaType,
+	}, nil
+}
+
+func DeserializeColomnMetadata(r io.Reader, colType MysqlType) (*ColumnMetadata, error) {
 	switch colType {
 
 	// 1 byte pack size cases
-	case MYSQL_TYPE_FLOAT, MYSQL_TYPE_DOUBLE, MYSQL_TYPE_BLOB, MYSQL_TYPE_GEOMETRY:
-		data, err := deserialization.ReadBytes(r, 1)
-		fatalErr(err)
-
-		return &ColumnMetadata{
-			data:     data,
-			metaType: PACK_SIZE_METADATA,
-		}
+	case MYSQL_TYPE_FLOAT, MYSQL_TYPE_DOUBLE, MYSQL_TYPE_BLOB, MYSQL_TYPE_GEOMETRY, MYSQL_TYPE_JSON:
+		return newColumnMetadata(r, 1, PACK_SIZE_METADATA)
 
 	case MYSQL_TYPE_TIMESTAMP_V2, MYSQL_TYPE_TIME_V2, MYSQL_TYPE_DATETIME_V2:
-		data, err := deserialization.ReadBytes(r, 1)
-		fatalErr(err)
-
-		return &ColumnMetadata{
-			data:     data,
-			metaType: TIME_V2_METADATA,
-		}
+		return newColumnMetadata(r, 1, TIME_V2_METADATA)
 
 	// 2 byte cases
-	case MYSQL_TYPE_VARCHAR, MYSQL_TYPE_BIT, MYSQL_TYPE_NEWDECIMAL, MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_STRING:
-		data, err := deserialization.ReadBytes(r, 2)
-		fatalErr(err)
+	case MYSQL_TYPE_VARCHAR:
+		return newColumnMetadata(r, 2, VARCHAR_METADATA)
 
-		var metaType MetadataType
+	case MYSQL_TYPE_BIT:
+		return newColumnMetadata(r, 2, BITSET_METADATA)
 
-		switch colType {
-		case MYSQL_TYPE_VARCHAR:
-			metaType = VARCHAR_METADATA
+	case MYSQL_TYPE_NEWDECIMAL:
+		return newColumnMetadata(r, 2, NEW_DECIMAL_METADATA)
 
-		case MYSQL_TYPE_BIT:
-			metaType = BITSET_METADATA
+	case MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_STRING:
+		return newColumnMetadata(r, 2, STRING_METADATA)
+	}
 
-		case MYSQL_TYPE_NEWDECIMAL:
-			metaType = NEW_DECIMAL_METADATA
+	return nil, nil
+}
 
-		case MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_STRING:
-			metaType = STRING_METADATA
-		}
+/*
+The accessors below can only fail when they are called on the
+wrong kind of metadata, which is a programming error rather than
+bad input (the metadata kind always follows the column type it
+was read with), so they panic instead of returning an error.
+The data length is guaranteed by DeserializeColomnMetadata.
+*/
 
-		return &ColumnMetadata{
-			data:     data,
-			metaType: metaType,
-		}
+func (m *ColumnMetadata) mustBe(method string, metaType MetadataType) {
+	if m.metaType != metaType {
+		panic(fmt.Sprintf("Cannot call %v() on metadata that is not %v", method, metaType))
 	}
-
-	return nil
 }
 
 func (m *ColumnMetadata) PackSize() uint8 {
 	switch m.metaType {
 	case PACK_SIZE_METADATA:
-		if len(m.data) != 1 {
-			fatalMetadataLengthMismatch()
-		}
-
 		return uint8(m.data[0])
 
-	case STRING_METADATA, BITSET_METADATA: // NOTE: may be big endian (see shyiko version)
-		if len(m.data) != 2 {
-			fatalMetadataLengthMismatch()
-		}
-
+	case STRING_METADATA: // NOTE: may be big endian (see shyiko version)
 		return uint8(m.data[1])
 
-	case NEW_DECIMAL_METADATA:
-		log.Fatal("Cannot call PackSize() on NEW_DECIMAL_METADATA")
-
-	case VARCHAR_METADATA:
-		log.Fatal("Cannot call PackSize() on VARCHAR_METADATA")
-
-	default:
-		log.Fatal("Invalid metadata type!")
+	case BITSET_METADATA:
+		return (m.BitsetLength() + 7) / 8
 	}
 
-	return 0
+	panic(fmt.Sprintf("Cannot call PackSize() on %v", m.metaType))
 }
 
 func (m *ColumnMetadata) RealType() MysqlType {
-	if m.metaType != STRING_METADATA {
-		log.Fatal("Cannot call RealType() on metadata that is not STRING_METADATA")
-	}
+	m.mustBe("RealType", STRING_METADATA)
 
-	if len(m.data) != 2 {
-		fatalMetadataLengthMismatch()
-	}
-
-	return MysqlType(m.data[0])
+	return MysqlType(m.data[0] | 0x30)
 }
 
+// Max length in bytes of VARCHAR and CHAR columns
 func (m *ColumnMetadata) MaxLength() uint16 {
-	if m.metaType != VARCHAR_METADATA {
-		log.Fatal("Cannot call MaxLength() on metadata that is not VARCHAR_METADATA")
+	if m.metaType == STRING_METADATA {
+		return uint16((m.data[0]&0x30)^0x30)<<4 | uint16(m.data[1])
 	}
 
-	if len(m.data) != 2 {
-		fatalMetadataLengthMismatch()
-	}
+	m.mustBe("MaxLength", VARCHAR_METADATA)
 
 	return binary.LittleEndian.Uint16(m.data)
 }
 
 func (m *ColumnMetadata) Precision() uint8 {
-	if m.metaType != NEW_DECIMAL_METADATA {
-		log.Fatal("Cannot call Precision() on metadata that is not NEW_DECIMAL_METADATA")
-	}
-
-	if len(m.data) != 2 {
-		fatalMetadataLengthMismatch()
-	}
+	m.mustBe("Precision", NEW_DECIMAL_METADATA)
 
-	return uint8(m.data[1])
+	return uint8(m.data[0])
 }
 
 func (m *ColumnMetadata) Decimals() uint8 {
-	if m.metaType != NEW_DECIMAL_METADATA {
-		log.Fatal("Cannot call Decimals() on metadata that is not NEW_DECIMAL_METADATA")
-	}
-
-	if len(m.data) != 2 {
-		fatalMetadataLengthMismatch()
-	}
+	m.mustBe("Decimals", NEW_DECIMAL_METADATA)
 
 	return uint8(m.data[1])
 }
 
+// Number of bits in the column, e.g. 10 for BIT(10)
 func (m *ColumnMetadata) BitsetLength() uint8 {
-	if m.metaType != BITSET_METADATA {
-		log.Fatal("Cannot call BitsetLength() on metadata that is not BITSET_METADATA")
-	}
-
-	if len(m.data) != 2 {
-		fatalMetadataLengthMismatch()
-	}
+	m.mustBe("BitsetLength", BITSET_METADATA)
 
-	return uint8(m.data[1])
+	return uint8(m.data[1])*8 + uint8(m.data[0])
 }
 
 func (m *ColumnMetadata) FractionalSecondsPrecision() uint8 {
-	if m.metaType != TIME_V2_METADATA {
-		log.Fatal("Cannot call FractionalSecondsPrecision() on metadata that is not TIME_V2_METADATA")
-	}
-
-	if len(m.data) != 1 {
-		fatalMetadataLengthMismatch()
-	}
+	m.mustBe("FractionalSecondsPrecision", TIME_V2_METADATA)
 
 	return uint8(m.data[0])
 }
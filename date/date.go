@@ -1,11 +1,21 @@
 package date
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 )
 
+// Returned when a date with zero parts (e.g. 0000-00-00 or
+// 2019-00-00) is converted to a time.Time
+var ErrZeroDate = errors.New("zero date")
+
 func filledByteSlice(defaultByte byte, count int) []byte {
+	if count < 0 {
+		count = 0
+	}
+
 	b := make([]byte, count)
 	for i := range b {
 		b[i] = defaultByte
@@ -48,10 +58,34 @@ func (date MysqlDate) String() string {
 	return fmt.Sprintf("%v-%v-%v", date.Year(), date.Month(), date.Day())
 }
 
+// Whether this is MySQL's 0000-00-00
+func (date MysqlDate) IsZero() bool {
+	return date.year == 0 && date.month == 0 && date.day == 0
+}
+
+// Midnight of the date in loc (which must not be nil). Dates with
+// zero parts can't be represented and return ErrZeroDate.
+func (date MysqlDate) Time(loc *time.Location) (time.Time, error) {
+	if date.year == 0 || date.month == 0 || date.day == 0 {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrZeroDate, date)
+	}
+
+	return time.Date(date.year, time.Month(date.month), date.day, 0, 0, 0, 0, loc), nil
+}
+
+/*
+TIME values range from -838:59:59.000000 to 838:59:59.000000, so
+hours can go past 24 and there is a separate sign. The fractional
+seconds precision (fsp, 0-6) only affects how the value is printed.
+*/
+
 type MysqlTime struct {
-	hour   int
-	minute int
-	second int
+	negative    bool
+	hour        int
+	minute      int
+	second      int
+	microsecond int
+	fsp         int
 }
 
 func NewMysqlTime(hour, minute, second int) MysqlTime {
@@ -62,6 +96,28 @@ func NewMysqlTime(hour, minute, second int) MysqlTime {
 	}
 }
 
+// fsp is clamped to 0-6
+func NewMysqlTimeWithFraction(negative bool, hour, minute, second, microsecond, fsp int) MysqlTime {
+	if fsp < 0 {
+		fsp = 0
+	} else if fsp > 6 {
+		fsp = 6
+	}
+
+	return MysqlTime{
+		negative:    negative,
+		hour:        hour,
+		minute:      minute,
+		second:      second,
+		microsecond: microsecond,
+		fsp:         fsp,
+	}
+}
+
+func (timestamp MysqlTime) Negative() bool {
+	return timestamp.negative
+}
+
 func (timestamp MysqlTime) Hour() string {
 	return padStringNumber(strconv.FormatInt(int64(timestamp.hour), 10), 2)
 }
@@ -74,8 +130,41 @@ func (timestamp MysqlTime) Second() string {
 	return padStringNumber(strconv.FormatInt(int64(timestamp.second), 10), 2)
 }
 
+func (timestamp MysqlTime) Microsecond() string {
+	return padStringNumber(strconv.FormatInt(int64(timestamp.microsecond), 10), 6)
+}
+
+// Fractional seconds with as many digits as the fsp, "" if it is 0
+func (timestamp MysqlTime) Fraction() string {
+	return timestamp.Microsecond()[:timestamp.fsp]
+}
+
 func (timestamp MysqlTime) String() string {
-	return fmt.Sprintf("%v:%v:%v", timestamp.Hour(), timestamp.Minute(), timestamp.Second())
+	sign := ""
+	if timestamp.negative {
+		sign = "-"
+	}
+
+	str := fmt.Sprintf("%v%v:%v:%v", sign, timestamp.Hour(), timestamp.Minute(), timestamp.Second())
+
+	if timestamp.fsp > 0 {
+		str += "." + timestamp.Fraction()
+	}
+
+	return str
+}
+
+func (timestamp MysqlTime) Duration() time.Duration {
+	d := time.Duration(timestamp.hour)*time.Hour +
+		time.Duration(timestamp.minute)*time.Minute +
+		time.Duration(timestamp.second)*time.Second +
+		time.Duration(timestamp.microsecond)*time.Microsecond
+
+	if timestamp.negative {
+		return -d
+	}
+
+	return d
 }
 
 type MysqlDatetime struct {
@@ -90,6 +179,30 @@ func NewMysqlDatetime(year, month, day, hour, minute, second int) MysqlDatetime
 	}
 }
 
+func NewMysqlDatetimeWithFraction(year, month, day, hour, minute, second, microsecond, fsp int) MysqlDatetime {
+	return MysqlDatetime{
+		MysqlDate: NewMysqlDate(year, month, day),
+		MysqlTime: NewMysqlTimeWithFraction(false, hour, minute, second, microsecond, fsp),
+	}
+}
+
 func (dateTime MysqlDatetime) String() string {
 	return fmt.Sprintf("%v %v", dateTime.MysqlDate.String(), dateTime.MysqlTime.String())
 }
+
+// Whether this is MySQL's 0000-00-00 00:00:00
+func (dateTime MysqlDatetime) IsZero() bool {
+	return dateTime.MysqlDate.IsZero() && dateTime.MysqlTime.Duration() == 0
+}
+
+// The datetime in loc (which must not be nil). Dates with zero
+// parts can't be represented and return ErrZeroDate.
+func (dateTime MysqlDatetime) Time(loc *time.Location) (time.Time, error) {
+	t, err := dateTime.MysqlDate.Time(loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(),
+		dateTime.hour, dateTime.minute, dateTime.second, dateTime.microsecond*1000, loc), nil
+}
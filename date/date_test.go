@@ -0,0 +1,42 @@
+package date
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMysqlTimeString(t *testing.T) {
+	assert.Equal(t, "01:02:03", NewMysqlTime(1, 2, 3).String())
+	assert.Equal(t, "-838:59:59.000", NewMysqlTimeWithFraction(true, 838, 59, 59, 0, 3).String())
+	assert.Equal(t, "00:00:00.12", NewMysqlTimeWithFraction(false, 0, 0, 0, 120000, 2).String())
+
+	// More digits than MySQL stores are clamped
+	assert.Equal(t, "00:00:00.120000", NewMysqlTimeWithFraction(false, 0, 0, 0, 120000, 7).String())
+}
+
+func TestMysqlDateZero(t *testing.T) {
+	zero := NewMysqlDate(0, 0, 0)
+	assert.True(t, zero.IsZero())
+	assert.Equal(t, "0000-00-00", zero.String())
+
+	_, err := zero.Time(time.UTC)
+	assert.ErrorIs(t, err, ErrZeroDate)
+
+	_, err = NewMysqlDate(2019, 0, 0).Time(time.UTC)
+	assert.ErrorIs(t, err, ErrZeroDate)
+
+	assert.True(t, NewMysqlDatetime(0, 0, 0, 0, 0, 0).IsZero())
+	assert.False(t, NewMysqlDatetime(0, 0, 0, 0, 0, 1).IsZero())
+}
+
+func TestMysqlDatetimeTime(t *testing.T) {
+	loc := time.FixedZone("test", -5*60*60)
+	dt := NewMysqlDatetimeWithFraction(2019, 3, 5, 14, 25, 7, 1, 6)
+
+	tm, err := dt.Time(loc)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, 3, 5, 14, 25, 7, 1000, loc), tm)
+	assert.Equal(t, "2019-03-05 14:25:07.000001", dt.String())
+}
@@ -1,19 +1,13 @@
 package deserialization
 
-// !!! TODO: link this with main package common.go
-
 import (
-	"fmt"
-	"os"
-	"runtime/debug"
+	"errors"
 )
 
-// Error macro
-// TODO: add backtrace
-func fatalErr(err error) {
-	if err != nil {
-		fmt.Println("Generic fatal error:", err)
-		debug.PrintStack()
-		os.Exit(1)
-	}
-}
+// Returned by ReadPackedInteger when the first byte is one of
+// the values MySQL never writes into a binlog (251 and 255)
+var ErrInvalidPackedInteger = errors.New("invalid packed integer")
+
+// Returned by the TIME2, DATETIME2 and TIMESTAMP2 readers when the
+// column metadata gives more than 6 digits of fractional seconds
+var ErrInvalidFractionalSecondsPrecision = errors.New("invalid fractional seconds precision")
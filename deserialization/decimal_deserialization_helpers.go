@@ -0,0 +1,122 @@
+package deserialization
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+NEWDECIMAL
+==========
+
+Let:
+P = precision (total digits)
+S = scale (digits after the decimal point)
+
+Digits are packed in groups of 9 into 4 byte big endian integers.
+Digits left over from the integer part (P - S) are packed first,
+followed by the full integer groups, the full fraction groups and
+finally any left over fraction digits. Left over digits are packed
+into the smallest number of bytes that can hold them:
+
+digits: 0 1 2 3 4 5 6 7 8
+bytes:  0 1 1 2 2 3 3 4 4
+
+The highest bit of the first byte is flipped for positive values.
+For negative values, every byte is inverted as well.
+
+e.g. DECIMAL(10, 4) 1234.5678 = 80 04 d2 16 2e
+3 bytes = 1234 (6 leftover integer digits, high bit flipped)
+2 bytes = 5678 (4 leftover fraction digits)
+
+*/
+
+const digitsPerDecimalGroup int = 9
+
+var decimalDigitsToBytes = [digitsPerDecimalGroup]int{0, 1, 1, 2, 2, 3, 3, 4, 4}
+
+func DecimalPackSize(precision, scale int) int {
+	integerDigits := precision - scale
+
+	return (integerDigits/digitsPerDecimalGroup)*4 + decimalDigitsToBytes[integerDigits%digitsPerDecimalGroup] +
+		(scale/digitsPerDecimalGroup)*4 + decimalDigitsToBytes[scale%digitsPerDecimalGroup]
+}
+
+// Reads a big endian group of digits that is 1-4 bytes long
+func readDecimalGroup(buf *bytes.Buffer, size int) uint32 {
+	b := padBytesBigEndian(buf.Next(size), 4-size)
+	return binary.BigEndian.Uint32(b)
+}
+
+func formatDecimalGroup(value uint32, digits int) string {
+	s := strconv.FormatUint(uint64(value), 10)
+	return strings.Repeat("0", digits-len(s)) + s
+}
+
+// Returns the exact value in decimal notation (e.g. "-1234.5678")
+func ReadDecimal(r io.Reader, metadata Metadata) (string, error) {
+	return ReadDecimalValue(r, int(metadata.Precision()), int(metadata.Decimals()))
+}
+
+// Same as ReadDecimal, for decimals stored without column metadata
+// (e.g. inside JSON values)
+func ReadDecimalValue(r io.Reader, precision, scale int) (string, error) {
+	if scale > precision || precision > 65 {
+		return "", fmt.Errorf("invalid decimal precision %v and scale %v", precision, scale)
+	}
+
+	b, err := ReadBytes(r, DecimalPackSize(precision, scale))
+	if err != nil {
+		return "", err
+	}
+
+	negative := b[0]&0x80 == 0
+	b[0] ^= 0x80
+
+	if negative {
+		for i := range b {
+			b[i] ^= 0xff
+		}
+	}
+
+	buf := bytes.NewBuffer(b)
+	integerDigits := precision - scale
+
+	integerPart := ""
+	if leftover := integerDigits % digitsPerDecimalGroup; leftover > 0 {
+		integerPart += formatDecimalGroup(readDecimalGroup(buf, decimalDigitsToBytes[leftover]), leftover)
+	}
+
+	for i := 0; i < integerDigits/digitsPerDecimalGroup; i++ {
+		integerPart += formatDecimalGroup(readDecimalGroup(buf, 4), digitsPerDecimalGroup)
+	}
+
+	fractionPart := ""
+	for i := 0; i < scale/digitsPerDecimalGroup; i++ {
+		fractionPart += formatDecimalGroup(readDecimalGroup(buf, 4), digitsPerDecimalGroup)
+	}
+
+	if leftover := scale % digitsPerDecimalGroup; leftover > 0 {
+		fractionPart += formatDecimalGroup(readDecimalGroup(buf, decimalDigitsToBytes[leftover]), leftover)
+	}
+
+	integerPart = strings.TrimLeft(integerPart, "0")
+	if integerPart == "" {
+		integerPart = "0"
+	}
+
+	value := integerPart
+	if scale > 0 {
+		value += "." + fractionPart
+	}
+
+	if negative && strings.Trim(value, "0.") != "" {
+		value = "-" + value
+	}
+
+	return value, nil
+}
@@ -0,0 +1,52 @@
+package deserialization
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDecimalMetadata struct {
+	precision uint8
+	decimals  uint8
+}
+
+func (m testDecimalMetadata) FractionalSecondsPrecision() uint8 { return 0 }
+func (m testDecimalMetadata) Precision() uint8                  { return m.precision }
+func (m testDecimalMetadata) Decimals() uint8                   { return m.decimals }
+
+func TestReadDecimal(t *testing.T) {
+	cases := []struct {
+		precision uint8
+		decimals  uint8
+		b         []byte
+		expected  string
+	}{
+		{10, 4, []byte{0x80, 0x04, 0xd2, 0x16, 0x2e}, "1234.5678"},
+		{10, 4, []byte{0x7f, 0xfb, 0x2d, 0xe9, 0xd1}, "-1234.5678"},
+		{5, 2, []byte{0x80, 0x00, 0x00}, "0.00"},
+		{5, 2, []byte{0x80, 0x00, 0x05}, "0.05"},
+		{10, 0, []byte{0x80, 0x00, 0x00, 0x00, 0x07}, "7"},
+		{10, 0, []byte{0x7f, 0xff, 0xff, 0xff, 0xf8}, "-7"},
+		{
+			// DECIMAL(65, 30): 8 leftover integer digits, 3 integer groups,
+			// 3 fraction groups and 3 leftover fraction digits
+			65, 30,
+			[]byte{
+				0x85, 0xf5, 0xe0, 0xff,
+				0x3b, 0x9a, 0xc9, 0xff, 0x3b, 0x9a, 0xc9, 0xff, 0x3b, 0x9a, 0xc9, 0xff,
+				0x3b, 0x9a, 0xc9, 0xff, 0x3b, 0x9a, 0xc9, 0xff, 0x3b, 0x9a, 0xc9, 0xff,
+				0x03, 0xe7,
+			},
+			strings.Repeat("9", 35) + "." + strings.Repeat("9", 30),
+		},
+	}
+
+	for _, c := range cases {
+		value, err := ReadDecimal(bytes.NewBuffer(c.b), testDecimalMetadata{c.precision, c.decimals})
+		checkErr(t, err)
+		assert.Equal(t, c.expected, value)
+	}
+}
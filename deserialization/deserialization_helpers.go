@@ -3,9 +3,8 @@ package deserialization
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
+	"fmt"
 	"io"
-	"log"
 
 	"github.com/granicus/mysql-binlog-go/bitset"
 )
@@ -33,16 +32,17 @@ func checkRead(n int, err error, bytes []byte) error {
 	}
 
 	if n != len(bytes) {
-		return errors.New("Read mismatch")
-		// return fmt.Errorf("Read mismatch: length=%v, bytes=%v", n, bytes)
+		return io.ErrUnexpectedEOF
 	}
 
 	return nil
 }
 
+// Zero length reads succeed even at the end of the reader (e.g. an
+// empty string in the last column of an event)
 func ReadBytes(r io.Reader, length int) ([]byte, error) {
 	b := make([]byte, length)
-	n, err := r.Read(b)
+	n, err := io.ReadFull(r, b)
 	return b, checkRead(n, err, b)
 }
 
@@ -155,7 +155,7 @@ func ReadBitset(r io.Reader, bitCount int) (bitset.Bitset, error) {
 		return make(bitset.Bitset, 0), err
 	}
 
-	return bitset.MakeFromByteArray(b, uint(bitCount)), nil
+	return bitset.MakeFromByteArray(b, uint(bitCount))
 }
 
 func ReadNullTerminatedString(r io.Reader) (string, error) {
@@ -220,7 +220,9 @@ in an array or anything, it shouldn't cause any issues though.
 
 func ReadPackedInteger(r io.Reader) (uint64, error) {
 	firstByte, err := ReadUint8(r)
-	fatalErr(err)
+	if err != nil {
+		return uint64(0), err
+	}
 
 	if firstByte <= 250 {
 		return uint64(firstByte), nil
@@ -229,27 +231,25 @@ func ReadPackedInteger(r io.Reader) (uint64, error) {
 	bytesToRead := 0
 
 	switch firstByte {
-	case 251:
-		// MySQL error code
-		// something is wrong
-		log.Fatal("Packed integer invalid value:", firstByte)
 	case 252:
 		bytesToRead = 2
 	case 253:
 		bytesToRead = 3
 	case 254:
 		bytesToRead = 8
-	case 255:
-		log.Fatal("Packed integer invalid value:", firstByte)
+	default:
+		// 251 is the MySQL NULL marker and 255 is unused,
+		// neither is supposed to appear in a binlog
+		return uint64(0), fmt.Errorf("%w: first byte %v", ErrInvalidPackedInteger, firstByte)
 	}
 
 	b, err := ReadBytes(r, bytesToRead)
-
 	if err != nil {
 		return uint64(0), err
 	}
 
-	var value uint64
-	err = binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &value)
-	return value, err
+	// Pad to 8 bytes
+	b = append(b, make([]byte, 8-len(b))...)
+
+	return binary.LittleEndian.Uint64(b), nil
 }
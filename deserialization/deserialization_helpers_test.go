@@ -50,3 +50,23 @@ func TestReadBitset(t *testing.T) {
 		}
 	}
 }
+
+func TestReadPackedInteger(t *testing.T) {
+	value, err := ReadPackedInteger(bytes.NewBuffer([]byte{0xfa}))
+	checkErr(t, err)
+	assert.Equal(t, uint64(250), value)
+
+	value, err = ReadPackedInteger(bytes.NewBuffer([]byte{0xfc, 0x34, 0x12}))
+	checkErr(t, err)
+	assert.Equal(t, uint64(0x1234), value)
+
+	value, err = ReadPackedInteger(bytes.NewBuffer([]byte{0xfd, 0x56, 0x34, 0x12}))
+	checkErr(t, err)
+	assert.Equal(t, uint64(0x123456), value)
+
+	_, err = ReadPackedInteger(bytes.NewBuffer([]byte{0xfb}))
+	assert.ErrorIs(t, err, ErrInvalidPackedInteger)
+
+	_, err = ReadPackedInteger(bytes.NewBuffer([]byte{0xfc, 0x34}))
+	assert.Error(t, err)
+}
@@ -1,8 +1,8 @@
 package deserialization
 
 import (
-	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"time"
 
@@ -14,6 +14,8 @@ import (
 // Add methods as needed
 type Metadata interface {
 	FractionalSecondsPrecision() uint8
+	Precision() uint8
+	Decimals() uint8
 }
 
 func expandBitsetToBytesBigEndian(set bitset.Bitset, bitsetBitCount int) []byte {
@@ -51,8 +53,45 @@ func fractionalSecondsPackSize(fsp int) int {
 	return 0
 }
 
-func readFractionalSeconds(r io.Reader, metadata Metadata) (int32, error) {
-	packSize := fractionalSecondsPackSize(int(metadata.FractionalSecondsPrecision()))
+/*
+Fractional seconds are stored big endian after the integer part,
+in units that depend on the fsp:
+
+fsp 1-2 = 1 byte,  hundredths of a second
+fsp 3-4 = 2 bytes, ten thousandths of a second
+fsp 5-6 = 3 bytes, microseconds
+
+*/
+
+func fractionalSecondsUnit(packSize int) int {
+	switch packSize {
+	case 1:
+		return 10000
+	case 2:
+		return 100
+	}
+
+	return 1
+}
+
+// MySQL stores at most 6 digits (microseconds)
+func fractionalSecondsPrecision(metadata Metadata) (int, error) {
+	fsp := int(metadata.FractionalSecondsPrecision())
+	if fsp > 6 {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidFractionalSecondsPrecision, fsp)
+	}
+
+	return fsp, nil
+}
+
+// Returns microseconds
+func readFractionalSeconds(r io.Reader, metadata Metadata) (int, error) {
+	fsp, err := fractionalSecondsPrecision(metadata)
+	if err != nil {
+		return 0, err
+	}
+
+	packSize := fractionalSecondsPackSize(fsp)
 
 	if packSize == 0 {
 		return 0, nil
@@ -63,13 +102,9 @@ func readFractionalSeconds(r io.Reader, metadata Metadata) (int32, error) {
 		return 0, err
 	}
 
-	// pad byte array so that it is 4 bytes in total
-	buf := bytes.NewBuffer(padBytesBigEndian(b, 4-packSize))
-
-	var fractionalSeconds int32
-	binary.Read(buf, binary.BigEndian, &fractionalSeconds)
+	value := binary.BigEndian.Uint32(padBytesBigEndian(b, 4-packSize))
 
-	return fractionalSeconds, nil
+	return int(value) * fractionalSecondsUnit(packSize), nil
 }
 
 /*
@@ -116,49 +151,80 @@ func ReadDate(r io.Reader) (date.MysqlDate, error) {
 TIME V2
 =======
 
-3 bytes
+3 bytes + fsp bytes
 Big Endian
 
-1 bit   = sign
+1 bit   = sign (1 is positive)
 1 bit   = reserved
 10 bits = hour
 6 bits  = minute
 6 bits  = second
 
+The value is offset by 0x800000 (0x800000000000 with 3 fsp bytes)
+so that it sorts as unsigned bytes. Negative values with 1 or 2
+fsp bytes borrow a second from the integer part, e.g. -00:00:01.25
+(fsp 2) is stored as -00:00:02 and a fraction byte of 0x100 - 25.
+
 */
 
-func ReadTimeV2(r io.Reader) (date.MysqlTime, error) {
-	// var sign int
-	var hour uint32
-	var minute uint32
-	var second uint32
+const timeV2IntOffset = 0x800000
 
-	b, err := ReadBytes(r, 3)
+func ReadTimeV2(r io.Reader, metadata Metadata) (date.MysqlTime, error) {
+	fsp, err := fractionalSecondsPrecision(metadata)
 	if err != nil {
 		return date.MysqlTime{}, err
 	}
 
-	// Pad to 4 bytes
-	b = append([]byte{0}, b...)
+	packSize := fractionalSecondsPackSize(fsp)
 
-	value := binary.BigEndian.Uint32(b)
+	b, err := ReadBytes(r, 3+packSize)
+	if err != nil {
+		return date.MysqlTime{}, err
+	}
+
+	// Integer part (hour, minute, second) << 24 + microseconds
+	var value int64
+
+	if packSize == 3 {
+		// All 6 bytes are offset together
+		value = int64(binary.BigEndian.Uint64(padBytesBigEndian(b, 2))) - timeV2IntOffset<<24
+	} else {
+		intPart := int64(binary.BigEndian.Uint32(padBytesBigEndian(b[:3], 1))) - timeV2IntOffset
+
+		var fraction int64
+		if packSize > 0 {
+			fraction = int64(binary.BigEndian.Uint32(padBytesBigEndian(b[3:], 4-packSize)))
 
-	// if (value | 1) > 0 {
-	// 	sign = 1
-	// } else {
-	// 	sign = -1
-	// }
+			if intPart < 0 && fraction != 0 {
+				intPart++
+				fraction -= 1 << uint(8*packSize)
+			}
+
+			fraction *= int64(fractionalSecondsUnit(packSize))
+		}
+
+		value = intPart<<24 + fraction
+	}
+
+	negative := value < 0
+	if negative {
+		value = -value
+	}
 
+	return newMysqlTime(negative, value>>24, value%(1<<24), fsp), nil
+}
+
+func newMysqlTime(negative bool, intPart, microsecond int64, fsp int) date.MysqlTime {
 	// [2-11]  Mask: 0011 1111 1111 0000 0000 0000 (0x3FF000)
-	hour = (value & 0x3FF000) >> 12
+	hour := (intPart & 0x3FF000) >> 12
 
 	// [12-17] Mask: 0000 0000 0000 1111 1100 0000 (0x000FC0)
-	minute = (value & 0x000FC0) >> 6
+	minute := (intPart & 0x000FC0) >> 6
 
 	// [18-23] Mask: 0000 0000 0000 0000 0011 1111 (0x00003F)
-	second = (value & 0x00003F)
+	second := (intPart & 0x00003F)
 
-	return date.NewMysqlTime(int(hour), int(minute), int(second)), nil
+	return date.NewMysqlTimeWithFraction(negative, int(hour), int(minute), int(second), int(microsecond), fsp)
 }
 
 /*
@@ -171,6 +237,10 @@ Big Endian
 */
 
 func ReadTimestampV2(r io.Reader, metadata Metadata) (time.Time, error) {
+	if _, err := fractionalSecondsPrecision(metadata); err != nil {
+		return time.Time{}, err
+	}
+
 	secondsBytes, err := ReadBytes(r, 4)
 	if err != nil {
 		return time.Time{}, err
@@ -183,14 +253,14 @@ func ReadTimestampV2(r io.Reader, metadata Metadata) (time.Time, error) {
 		return time.Time{}, err
 	}
 
-	return time.Unix(int64(seconds), int64(fractionalSeconds)), nil
+	return time.Unix(int64(seconds), int64(fractionalSeconds)*1000), nil
 }
 
 /*
 DATETIME V2
 ===========
 
-5 bytes
+5 bytes + fsp bytes
 Big Endian
 
 1 bit   = sign
@@ -212,6 +282,11 @@ func ReadDatetimeV2(r io.Reader, metadata Metadata) (date.MysqlDatetime, error)
 	var minute uint64
 	var second uint64
 
+	fsp, err := fractionalSecondsPrecision(metadata)
+	if err != nil {
+		return date.MysqlDatetime{}, err
+	}
+
 	b, err := ReadBytes(r, 5)
 	if err != nil {
 		return date.MysqlDatetime{}, err
@@ -240,5 +315,84 @@ func ReadDatetimeV2(r io.Reader, metadata Metadata) (date.MysqlDatetime, error)
 	year := int(yearMonth / 13)
 	month := int((yearMonth % 13))
 
-	return date.NewMysqlDatetime(year, month, int(day), int(hour), int(minute), int(second)), nil
+	microsecond, err := readFractionalSeconds(r, metadata)
+	if err != nil {
+		return date.MysqlDatetime{}, err
+	}
+
+	return date.NewMysqlDatetimeWithFraction(year, month, int(day), int(hour), int(minute), int(second),
+		microsecond, fsp), nil
+}
+
+/*
+TIMESTAMP (pre 5.6.4)
+=====================
+
+4 bytes
+Little Endian
+
+Seconds since the epoch
+
+*/
+
+func ReadTimestamp(r io.Reader) (time.Time, error) {
+	seconds, err := ReadUint32(r)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(int64(seconds), 0), nil
+}
+
+/*
+DATETIME (pre 5.6.4)
+====================
+
+8 bytes
+Little Endian
+
+Decimal digits YYYYMMDDhhmmss stored as an integer
+
+*/
+
+func ReadDatetime(r io.Reader) (date.MysqlDatetime, error) {
+	value, err := ReadUint64(r)
+	if err != nil {
+		return date.MysqlDatetime{}, err
+	}
+
+	datePart := value / 1000000
+	timePart := value % 1000000
+
+	return date.NewMysqlDatetime(
+		int(datePart/10000), int(datePart%10000/100), int(datePart%100),
+		int(timePart/10000), int(timePart%10000/100), int(timePart%100),
+	), nil
+}
+
+/*
+TIME (pre 5.6.4)
+================
+
+3 bytes
+Little Endian, signed
+
+Decimal digits HHMMSS stored as an integer
+
+*/
+
+func ReadTime(r io.Reader) (date.MysqlTime, error) {
+	b, err := ReadBytes(r, 3)
+	if err != nil {
+		return date.MysqlTime{}, err
+	}
+
+	// Sign extend to 4 bytes
+	value := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+	negative := value < 0
+	if negative {
+		value = -value
+	}
+
+	return date.NewMysqlTimeWithFraction(negative, int(value/10000), int(value%10000/100), int(value%100), 0, 0), nil
 }
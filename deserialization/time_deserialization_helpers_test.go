@@ -0,0 +1,74 @@
+package deserialization
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTimeMetadata uint8
+
+func (m testTimeMetadata) FractionalSecondsPrecision() uint8 { return uint8(m) }
+func (m testTimeMetadata) Precision() uint8                  { return 0 }
+func (m testTimeMetadata) Decimals() uint8                   { return 0 }
+
+func TestReadTimeV2(t *testing.T) {
+	cases := []struct {
+		fsp      uint8
+		b        []byte
+		expected string
+		duration time.Duration
+	}{
+		{0, []byte{0x80, 0xc8, 0xb8}, "12:34:56", 12*time.Hour + 34*time.Minute + 56*time.Second},
+		{0, []byte{0x4b, 0x91, 0x05}, "-838:59:59", -(838*time.Hour + 59*time.Minute + 59*time.Second)},
+		{2, []byte{0x7f, 0xff, 0xfe, 0xe7}, "-00:00:01.25", -1250 * time.Millisecond},
+		{4, []byte{0x81, 0x90, 0x00, 0x04, 0xd2}, "25:00:00.1234", 25*time.Hour + 123400*time.Microsecond},
+		{6, []byte{0x7f, 0xef, 0x7c, 0xf9, 0x07, 0xab}, "-01:02:03.456789",
+			-(time.Hour + 2*time.Minute + 3*time.Second + 456789*time.Microsecond)},
+	}
+
+	for _, c := range cases {
+		r := bytes.NewReader(c.b)
+
+		v, err := ReadTimeV2(r, testTimeMetadata(c.fsp))
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, v.String())
+		assert.Equal(t, c.duration, v.Duration())
+		assert.Equal(t, 0, r.Len())
+	}
+}
+
+func TestReadDatetimeV2Fraction(t *testing.T) {
+	r := bytes.NewReader([]byte{0x99, 0xa2, 0x8a, 0xe6, 0x47, 0x32})
+
+	v, err := ReadDatetimeV2(r, testTimeMetadata(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "2019-03-05 14:25:07.5", v.String())
+
+	tm, err := v.Time(time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, 3, 5, 14, 25, 7, 500000000, time.UTC), tm)
+}
+
+func TestReadTimestampV2Fraction(t *testing.T) {
+	r := bytes.NewReader([]byte{0x59, 0x68, 0x2f, 0x00, 0x01, 0xe2, 0x40})
+
+	v, err := ReadTimestampV2(r, testTimeMetadata(6))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1500000000, 123456000), v)
+}
+
+func TestReadTimeInvalidFractionalSecondsPrecision(t *testing.T) {
+	b := []byte{0x80, 0xc8, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	_, err := ReadTimeV2(bytes.NewReader(b), testTimeMetadata(7))
+	assert.ErrorIs(t, err, ErrInvalidFractionalSecondsPrecision)
+
+	_, err = ReadDatetimeV2(bytes.NewReader(b), testTimeMetadata(7))
+	assert.ErrorIs(t, err, ErrInvalidFractionalSecondsPrecision)
+
+	_, err = ReadTimestampV2(bytes.NewReader(b), testTimeMetadata(7))
+	assert.ErrorIs(t, err, ErrInvalidFractionalSecondsPrecision)
+}
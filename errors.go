@@ -0,0 +1,107 @@
+package binlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+ERRORS
+======
+
+Nothing in this package exits the process on bad input.
+Decode paths return one of the sentinel errors below (usually
+wrapped with more detail, so compare with errors.Is), and
+anything that fails while decoding a specific event is
+wrapped in an *EventError that carries the binlog position
+and the event type so the caller can log, skip, or alert.
+
+*/
+
+var (
+	ErrInvalidMagic           = errors.New("binlog magic number was not correct")
+	ErrUnsupportedLogVersion  = errors.New("unsupported binlog version")
+	ErrTruncatedEvent         = errors.New("truncated event")
+	ErrMalformedEvent         = errors.New("malformed event")
+	ErrMissingTableMap        = errors.New("no table map event for table id")
+	ErrUnsupportedColumnType  = errors.New("unsupported column type")
+	ErrMetadataLengthMismatch = errors.New("mismatch of metadata length")
+	ErrSchemaMismatch         = errors.New("schema does not match table map")
+	ErrNullValue              = errors.New("value is NULL")
+	ErrInvalidConversion      = errors.New("invalid value conversion")
+	ErrValueOutOfRange        = errors.New("value out of range")
+	ErrEventReleased          = errors.New("event has been released from the stream buffer")
+	ErrInvalidPosition        = errors.New("position is not at the start of an event")
+)
+
+// Wraps any error encountered while decoding a single event
+type EventError struct {
+	Position int64
+	Type     MysqlBinlogEventType
+	Err      error
+}
+
+func newEventError(position int64, eventType MysqlBinlogEventType, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*EventError); ok {
+		return err
+	}
+
+	return &EventError{
+		Position: position,
+		Type:     eventType,
+		Err:      truncatedErr(err),
+	}
+}
+
+func (e *EventError) Error() string {
+	return fmt.Sprintf("%v at position %v: %v", e.Type, e.Position, e.Err)
+}
+
+func (e *EventError) Unwrap() error {
+	return e.Err
+}
+
+type UnsupportedColumnTypeError struct {
+	Type        MysqlType
+	ColumnIndex int
+}
+
+func (e *UnsupportedColumnTypeError) Error() string {
+	return fmt.Sprintf("%v: %v (column %v)", ErrUnsupportedColumnType, e.Type, e.ColumnIndex)
+}
+
+func (e *UnsupportedColumnTypeError) Is(target error) bool {
+	return target == ErrUnsupportedColumnType
+}
+
+// Returned by the Value conversion methods. Err is ErrNullValue,
+// ErrInvalidConversion, ErrValueOutOfRange or an error from the
+// date package.
+type ConversionError struct {
+	Type MysqlType
+	To   string
+	Err  error
+}
+
+func (e *ConversionError) Error() string {
+	return fmt.Sprintf("cannot convert %v value to %v: %v", e.Type, e.To, e.Err)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// Short reads from the deserialization helpers all mean the
+// event ended before we were done with it
+func truncatedErr(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %v", ErrTruncatedEvent, err)
+	}
+
+	return err
+}
@@ -20,30 +20,40 @@ type EventHeader struct {
 }
 
 // TODO: move this over to use encoding/binary with struct pointer
-func ReadEventHeader(r io.Reader) *EventHeader {
+func ReadEventHeader(r io.Reader) (*EventHeader, error) {
 	// Read number of bytes in header
 	b, err := deserialization.ReadBytes(r, 4+1+4+4+4+2)
-	fatalErr(err)
+	if err != nil {
+		return nil, truncatedErr(err)
+	}
 
 	var h EventHeader
-	fatalErr(binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &h))
+	if err = binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &h); err != nil {
+		return nil, truncatedErr(err)
+	}
 
-	return &h
+	return &h, nil
 }
 
 type Event struct {
 	eventType      MysqlBinlogEventType
 	readerPosition int64
+	file           string
 	binlog         *Binlog
 	header         *EventHeader
 	data           *EventData
+
+	// What the event was read from, see released
+	reader io.ReadSeeker
 }
 
 func newIndexedEvent(binlog *Binlog, eventType MysqlBinlogEventType, position int64) *Event {
 	return &Event{
 		eventType:      eventType,
 		readerPosition: position,
+		file:           binlog.name,
 		binlog:         binlog,
+		reader:         binlog.reader,
 	}
 }
 
@@ -51,20 +61,52 @@ func newDeserializedEvent(binlog *Binlog, position int64, header *EventHeader, d
 	return &Event{
 		eventType:      header.Type,
 		readerPosition: position,
+		file:           binlog.name,
 		binlog:         binlog,
+		reader:         binlog.reader,
 		header:         header,
 		data:           &data,
 	}
 }
 
-func (e *Event) deserializeHeader() {
-	e.header = e.binlog.deserializeEventHeader(e.readerPosition)
+// The binlog has moved on to another file (StreamingBinlog rotating)
+// or closed the one the event is in (BinlogSet moving to the next)
+func (e *Event) released() bool {
+	return e.reader != e.binlog.reader || e.binlog.closed
+}
+
+func (e *Event) deserializeHeader() error {
+	if e.released() {
+		return newEventError(e.readerPosition, e.eventType, ErrEventReleased)
+	}
+
+	header, err := e.binlog.deserializeEventHeader(e.readerPosition)
+	if err != nil {
+		return newEventError(e.readerPosition, e.eventType, err)
+	}
+
+	e.header = header
+	return nil
 }
 
-func (e *Event) deserializeData() {
-	data := e.binlog.deserializeEventData(e.readerPosition, e.Header())
+func (e *Event) deserializeData() error {
+	header, err := e.Header()
+	if err != nil {
+		return err
+	}
+
+	if e.released() {
+		return newEventError(e.readerPosition, e.eventType, ErrEventReleased)
+	}
+
+	data, err := e.binlog.deserializeEventData(e.readerPosition, header)
+	if err != nil {
+		return newEventError(e.readerPosition, e.eventType, err)
+	}
+
 	dataInterface := EventData(data) // compiler bug (can't do "&(interface{}(data))")
 	e.data = &dataInterface
+	return nil
 }
 
 func (e *Event) Type() MysqlBinlogEventType {
@@ -75,18 +117,32 @@ func (e *Event) Position() int64 {
 	return e.readerPosition
 }
 
-func (e *Event) Header() *EventHeader {
+// The event's file and offset, File is empty if the events aren't
+// being read from a file (NewBinlog, NetworkBinlog)
+func (e *Event) FilePosition() Position {
+	return Position{
+		File:   e.file,
+		Offset: e.readerPosition,
+	}
+}
+
+func (e *Event) Header() (*EventHeader, error) {
 	if e.header == nil {
-		e.deserializeHeader()
+		if err := e.deserializeHeader(); err != nil {
+			return nil, err
+		}
 	}
 
-	return e.header
+	return e.header, nil
 }
 
-func (e *Event) Data() EventData {
+// Errors are returned as *EventError
+func (e *Event) Data() (EventData, error) {
 	if e.data == nil {
-		e.deserializeData()
+		if err := e.deserializeData(); err != nil {
+			return nil, err
+		}
 	}
 
-	return *e.data
+	return *e.data, nil
 }
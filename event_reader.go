@@ -0,0 +1,68 @@
+package binlog
+
+import (
+	"context"
+)
+
+/*
+EVENT READERS
+=============
+
+Every source of events (Binlog for files, StreamingBinlog for files
+that are still being written, NetworkBinlog for replication
+connections) implements EventReader. Next returns the next event
+or an error, and never panics:
+
+io.EOF        = a closed file or a finished stream has no more events
+ctx.Err()     = the context was cancelled or hit its deadline while
+                waiting, calling Next again carries on where it was
+*EventError   = the event couldn't be read, with its position
+
+Events are decoded lazily: Next only reads the header, Event.Data
+decodes the rest.
+
+*/
+
+type EventReader interface {
+	Next(ctx context.Context) (*Event, error)
+}
+
+// Adapts a function to EventReader
+type EventReaderFunc func(ctx context.Context) (*Event, error)
+
+func (f EventReaderFunc) Next(ctx context.Context) (*Event, error) {
+	return f(ctx)
+}
+
+type readResult struct {
+	b   []byte
+	err error
+}
+
+// Runs a blocking read in the background so a caller can stop
+// waiting for it (when its context is done) without losing what
+// it reads: the next call picks up the same read.
+type backgroundReader struct {
+	pending chan readResult
+}
+
+func (r *backgroundReader) read(ctx context.Context, read func() ([]byte, error)) ([]byte, error) {
+	if r.pending == nil {
+		pending := make(chan readResult, 1)
+		go func() {
+			b, err := read()
+			pending <- readResult{b: b, err: err}
+		}()
+
+		r.pending = pending
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case result := <-r.pending:
+		r.pending = nil
+		return result.b, result.err
+	}
+}
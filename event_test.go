@@ -0,0 +1,75 @@
+package binlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Binlog whose reader sits at the start of an event body
+func newTestBinlog(eventType MysqlBinlogEventType, body []byte) (*Binlog, *EventHeader) {
+	b := &Binlog{
+		TableMapCollection: make(map[uint64]*TableMapEvent),
+		reader:             bytes.NewReader(body),
+		formatDescription:  defaultFormatDescription(),
+		bytesLength:        -1,
+	}
+
+	return b, &EventHeader{Type: eventType, Length: uint32(len(body)), NextPosition: uint32(len(body))}
+}
+
+func TestReadEventHeaderTruncated(t *testing.T) {
+	_, err := ReadEventHeader(bytes.NewReader([]byte{0x00, 0x01, 0x02}))
+	assert.ErrorIs(t, err, ErrTruncatedEvent)
+}
+
+func TestNewBinlogInvalidMagic(t *testing.T) {
+	_, err := NewBinlog(bytes.NewReader([]byte{0x00, 0x62, 0x69, 0x6e}))
+	assert.ErrorIs(t, err, ErrInvalidMagic)
+}
+
+func TestBinlogNext(t *testing.T) {
+	b, err := NewBinlog(bytes.NewReader(testChecksummedBinlog()))
+	assert.NoError(t, err)
+
+	event, err := b.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ROTATE_EVENT, event.Type())
+
+	_, err = b.Next(context.Background())
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestBinlogNextTruncated(t *testing.T) {
+	log := testChecksummedBinlog()
+
+	b, err := NewBinlog(bytes.NewReader(log[:len(log)-3]))
+	assert.NoError(t, err)
+
+	_, err = b.Next(context.Background())
+	assert.ErrorIs(t, err, ErrTruncatedEvent)
+
+	var eventErr *EventError
+	assert.True(t, errors.As(err, &eventErr))
+	assert.Equal(t, int64(len(testBinlogPreamble())), eventErr.Position)
+}
+
+func TestBinlogNextCancelled(t *testing.T) {
+	b, err := NewBinlog(bytes.NewReader(testChecksummedBinlog()))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = b.Next(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	// Nothing was consumed
+	event, err := b.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ROTATE_EVENT, event.Type())
+}
@@ -0,0 +1,159 @@
+package binlog
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/granicus/mysql-binlog-go/deserialization"
+)
+
+const SERVER_VERSION_LENGTH int = 50
+
+type FormatDescriptionEvent struct {
+	BinlogVersion     uint16
+	ServerVersion     string
+	CreateTimestamp   uint32
+	HeaderLength      uint8
+	PostHeaderLengths []uint8
+	ChecksumAlgorithm ChecksumAlgorithm
+}
+
+/*
+FORMAT DESCRIPTION EVENT DATA
+=============================
+
+Let:
+T = number of event types the server knows about
+
+Fixed:
+2 bytes  = binlog version
+50 bytes = server version (null padded)
+4 bytes  = create timestamp
+1 byte   = common header length
+
+Variable:
+T bytes = post-header length for each event type,
+          indexed by event type - 1
+
+Server version >= 5.6.1 only (see checksum.go):
+1 byte  = checksum algorithm
+4 bytes = checksum
+
+The post-header lengths are what tell us how the fixed part
+of every other event is laid out, e.g. a TABLE_MAP_EVENT with
+a post-header length of 6 has a 4 byte table id instead of 6.
+
+*/
+
+func (b *Binlog) DeserializeFormatDescriptionEvent(header *EventHeader) (EventData, error) {
+	e := new(FormatDescriptionEvent)
+	var err error
+
+	e.BinlogVersion, err = ReadUint16(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	e.ServerVersion, err = ReadString(b.reader, SERVER_VERSION_LENGTH)
+	if err != nil {
+		return nil, err
+	}
+	e.ServerVersion = strings.TrimRight(e.ServerVersion, "\x00")
+
+	e.CreateTimestamp, err = ReadUint32(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	e.HeaderLength, err = ReadUint8(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Can't use remainingEventLength, the checksum algorithm
+	// of the previous format description doesn't apply here
+	currentPosition, err := b.GetPosition()
+	if err != nil {
+		return nil, err
+	}
+
+	postHeaderLengthsLength := int(b.eventEnd(header) - currentPosition)
+	if hasChecksumAlgorithm(e.ServerVersion) {
+		postHeaderLengthsLength -= 1 + BINLOG_CHECKSUM_LENGTH
+	}
+
+	if postHeaderLengthsLength < 0 {
+		return nil, fmt.Errorf("%w: format description event too short", ErrMalformedEvent)
+	}
+
+	e.PostHeaderLengths, err = ReadBytes(b.reader, postHeaderLengthsLength)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasChecksumAlgorithm(e.ServerVersion) {
+		alg, err := ReadUint8(b.reader)
+		if err != nil {
+			return nil, err
+		}
+
+		e.ChecksumAlgorithm = ChecksumAlgorithm(alg)
+	}
+
+	// Everything after this is laid out according to this event
+	b.formatDescription = e
+
+	return e, nil
+}
+
+// Returns 0 for event types the server did not know about
+func (e *FormatDescriptionEvent) PostHeaderLength(eventType MysqlBinlogEventType) uint8 {
+	i := int(eventType) - 1
+	if i < 0 || i >= len(e.PostHeaderLengths) {
+		return 0
+	}
+
+	return e.PostHeaderLengths[i]
+}
+
+// Used until a FORMAT_DESCRIPTION_EVENT has been read.
+// These are the lengths a MySQL 5.6/5.7 server writes.
+func defaultFormatDescription() *FormatDescriptionEvent {
+	e := &FormatDescriptionEvent{
+		BinlogVersion:     4,
+		HeaderLength:      uint8(EVENT_HEADER_LENGTH),
+		PostHeaderLengths: make([]uint8, PREVIOUS_GTIDS_EVENT),
+	}
+
+	for eventType, length := range map[MysqlBinlogEventType]uint8{
+		START_EVENT_V3:           56,
+		QUERY_EVENT:              13,
+		ROTATE_EVENT:             8,
+		LOAD_EVENT:               18,
+		CREATE_FILE_EVENT:        4,
+		APPEND_BLOCK_EVENT:       4,
+		EXEC_LOAD_EVENT:          4,
+		DELETE_FILE_EVENT:        4,
+		NEW_LOAD_EVENT:           18,
+		FORMAT_DESCRIPTION_EVENT: 84,
+		BEGIN_LOAD_QUERY_EVENT:   4,
+		EXECUTE_LOAD_QUERY_EVENT: 26,
+		TABLE_MAP_EVENT:          8,
+		WRITE_ROWS_EVENTv0:       8,
+		UPDATE_ROWS_EVENTv0:      8,
+		DELETE_ROWS_EVENTv0:      8,
+		WRITE_ROWS_EVENTv1:       8,
+		UPDATE_ROWS_EVENTv1:      8,
+		DELETE_ROWS_EVENTv1:      8,
+		INCIDENT_EVENT:           2,
+		WRITE_ROWS_EVENTv2:       10,
+		UPDATE_ROWS_EVENTv2:      10,
+		DELETE_ROWS_EVENTv2:      10,
+		GTID_EVENT:               42,
+		ANONYMOUS_GTID_EVENT:     42,
+	} {
+		e.PostHeaderLengths[eventType-1] = length
+	}
+
+	return e
+}
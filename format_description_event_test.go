@@ -0,0 +1,62 @@
+package binlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeserializeFormatDescriptionEvent(t *testing.T) {
+	body := []byte{0x04, 0x00}
+	body = append(body, []byte("5.6.27-log")...)
+	body = append(body, make([]byte, SERVER_VERSION_LENGTH-len("5.6.27-log"))...)
+	body = append(body, 0x10, 0x20, 0x30, 0x40, 0x13)
+
+	postHeaderLengths := make([]byte, TABLE_MAP_EVENT)
+	postHeaderLengths[QUERY_EVENT-1] = 13
+	postHeaderLengths[TABLE_MAP_EVENT-1] = 6
+	body = append(body, postHeaderLengths...)
+	body = append(body, byte(BINLOG_CHECKSUM_ALG_CRC32), 0x00, 0x00, 0x00, 0x00)
+
+	b, header := newTestBinlog(FORMAT_DESCRIPTION_EVENT, body)
+
+	data, err := b.DeserializeFormatDescriptionEvent(header)
+	assert.NoError(t, err)
+
+	e := data.(*FormatDescriptionEvent)
+	assert.Equal(t, uint16(4), e.BinlogVersion)
+	assert.Equal(t, "5.6.27-log", e.ServerVersion)
+	assert.Equal(t, uint32(0x40302010), e.CreateTimestamp)
+	assert.Equal(t, uint8(19), e.HeaderLength)
+	assert.Equal(t, uint8(13), e.PostHeaderLength(QUERY_EVENT))
+	assert.Equal(t, uint8(0), e.PostHeaderLength(WRITE_ROWS_EVENTv2))
+	assert.Len(t, e.PostHeaderLengths, int(TABLE_MAP_EVENT))
+	assert.Equal(t, BINLOG_CHECKSUM_ALG_CRC32, e.ChecksumAlgorithm)
+	assert.Equal(t, e, b.FormatDescription())
+}
+
+func TestDeserializeTableMapEventShortTableId(t *testing.T) {
+	body := []byte{
+		0x2a, 0x00, 0x00, 0x00, // 4 byte table id
+		0x00, 0x00, // reserved
+		0x02, 'd', 'b', 0x00,
+		0x01, 't', 0x00,
+		0x01,                  // number of columns
+		byte(MYSQL_TYPE_LONG), // column types
+		0x00,                  // metadata length
+		0x01,                  // can be null
+	}
+
+	b, header := newTestBinlog(TABLE_MAP_EVENT, body)
+	b.formatDescription.PostHeaderLengths[TABLE_MAP_EVENT-1] = 6
+
+	data, err := b.DeserializeTableMapEvent(header)
+	assert.NoError(t, err)
+
+	e := data.(*TableMapEvent)
+	assert.Equal(t, uint64(42), e.TableId)
+	assert.Equal(t, "db", e.DatabaseName)
+	assert.Equal(t, "t", e.TableName)
+	assert.Equal(t, []MysqlType{MYSQL_TYPE_LONG}, e.ColumnTypes)
+	assert.True(t, e.CanBeNull.Bit(0))
+}
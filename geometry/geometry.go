@@ -0,0 +1,359 @@
+package geometry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+WELL-KNOWN BINARY (WKB)
+=======================
+
+MySQL stores geometry values as a 4 byte little endian SRID
+followed by WKB (see DecodeMysql). Every WKB geometry starts with:
+
+1 byte  = byte order (0 = big endian, 1 = little endian)
+4 bytes = geometry type
+
+1 = Point              16 bytes = x, y (float64)
+2 = LineString         4 bytes = N, then N points (x, y)
+3 = Polygon            4 bytes = N, then N rings (as LineString data)
+4 = MultiPoint         4 bytes = N, then N Point geometries
+5 = MultiLineString    4 bytes = N, then N LineString geometries
+6 = MultiPolygon       4 bytes = N, then N Polygon geometries
+7 = GeometryCollection 4 bytes = N, then N geometries of any type
+
+Nested geometries carry their own byte order and type.
+
+*/
+
+const (
+	WKB_POINT uint32 = iota + 1
+	WKB_LINESTRING
+	WKB_POLYGON
+	WKB_MULTIPOINT
+	WKB_MULTILINESTRING
+	WKB_MULTIPOLYGON
+	WKB_GEOMETRYCOLLECTION
+)
+
+var ErrMalformedWKB = errors.New("malformed WKB")
+
+type Geometry interface {
+	// Well-known text, the way MySQL's ST_AsText prints it
+	WKT() string
+}
+
+type Point struct {
+	X float64
+	Y float64
+}
+
+type LineString []Point
+type Polygon []LineString
+type MultiPoint []Point
+type MultiLineString []LineString
+type MultiPolygon []Polygon
+type GeometryCollection []Geometry
+
+// Splits MySQL's internal format into its SRID and WKB and decodes the WKB
+func DecodeMysql(b []byte) (uint32, Geometry, error) {
+	if len(b) < 4 {
+		return 0, nil, fmt.Errorf("%w: %v bytes is too short for an SRID", ErrMalformedWKB, len(b))
+	}
+
+	g, err := Decode(b[4:])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return binary.LittleEndian.Uint32(b), g, nil
+}
+
+func Decode(wkb []byte) (Geometry, error) {
+	r := bytes.NewReader(wkb)
+
+	g, err := decode(r)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedWKB, io.ErrUnexpectedEOF)
+		}
+
+		return nil, err
+	}
+
+	if r.Len() > 0 {
+		return nil, fmt.Errorf("%w: %v trailing bytes", ErrMalformedWKB, r.Len())
+	}
+
+	return g, nil
+}
+
+type reader struct {
+	r     *bytes.Reader
+	order binary.ByteOrder
+}
+
+func (r reader) uint32() (uint32, error) {
+	var v uint32
+	err := binary.Read(r.r, r.order, &v)
+	return v, err
+}
+
+func (r reader) point() (Point, error) {
+	var xy [2]uint64
+	if err := binary.Read(r.r, r.order, &xy); err != nil {
+		return Point{}, err
+	}
+
+	return Point{X: math.Float64frombits(xy[0]), Y: math.Float64frombits(xy[1])}, nil
+}
+
+// Counts come from the input, so check them against what is left
+// before allocating anything
+func (r reader) count(minSize int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+
+	if uint64(n)*uint64(minSize) > uint64(r.r.Len()) {
+		return 0, fmt.Errorf("%w: %v elements don't fit in %v bytes", ErrMalformedWKB, n, r.r.Len())
+	}
+
+	return int(n), nil
+}
+
+func (r reader) lineString() (LineString, error) {
+	n, err := r.count(16)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make(LineString, n)
+	for i := range l {
+		if l[i], err = r.point(); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+func (r reader) polygon() (Polygon, error) {
+	n, err := r.count(4)
+	if err != nil {
+		return nil, err
+	}
+
+	p := make(Polygon, n)
+	for i := range p {
+		if p[i], err = r.lineString(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Reads a geometry with its byte order and type
+func decode(r *bytes.Reader) (Geometry, error) {
+	order, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	gr := reader{r: r}
+	switch order {
+	case 0:
+		gr.order = binary.BigEndian
+	case 1:
+		gr.order = binary.LittleEndian
+	default:
+		return nil, fmt.Errorf("%w: invalid byte order %v", ErrMalformedWKB, order)
+	}
+
+	geometryType, err := gr.uint32()
+	if err != nil {
+		return nil, err
+	}
+
+	switch geometryType {
+	case WKB_POINT:
+		return gr.point()
+
+	case WKB_LINESTRING:
+		return gr.lineString()
+
+	case WKB_POLYGON:
+		return gr.polygon()
+	}
+
+	if geometryType < WKB_MULTIPOINT || geometryType > WKB_GEOMETRYCOLLECTION {
+		return nil, fmt.Errorf("%w: unknown geometry type %v", ErrMalformedWKB, geometryType)
+	}
+
+	// Collections of whole geometries (at least 5 bytes each)
+	n, err := gr.count(5)
+	if err != nil {
+		return nil, err
+	}
+
+	geometries := make([]Geometry, n)
+	for i := range geometries {
+		if geometries[i], err = decode(r); err != nil {
+			return nil, err
+		}
+
+		if !allowedInCollection(geometryType, geometries[i]) {
+			return nil, fmt.Errorf("%w: %T in geometry type %v", ErrMalformedWKB, geometries[i], geometryType)
+		}
+	}
+
+	switch geometryType {
+	case WKB_MULTIPOINT:
+		m := make(MultiPoint, n)
+		for i, g := range geometries {
+			m[i] = g.(Point)
+		}
+		return m, nil
+
+	case WKB_MULTILINESTRING:
+		m := make(MultiLineString, n)
+		for i, g := range geometries {
+			m[i] = g.(LineString)
+		}
+		return m, nil
+
+	case WKB_MULTIPOLYGON:
+		m := make(MultiPolygon, n)
+		for i, g := range geometries {
+			m[i] = g.(Polygon)
+		}
+		return m, nil
+	}
+
+	return GeometryCollection(geometries), nil
+}
+
+func allowedInCollection(geometryType uint32, g Geometry) bool {
+	switch geometryType {
+	case WKB_MULTIPOINT:
+		_, ok := g.(Point)
+		return ok
+	case WKB_MULTILINESTRING:
+		_, ok := g.(LineString)
+		return ok
+	case WKB_MULTIPOLYGON:
+		_, ok := g.(Polygon)
+		return ok
+	}
+
+	return true
+}
+
+/*
+WKT
+===
+
+Coordinates are printed in their shortest form, e.g.
+POLYGON((0 0,10 0,10 10,0 0)) or MULTIPOINT((1 2),(3 4)).
+Empty geometries print as e.g. GEOMETRYCOLLECTION EMPTY.
+
+*/
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func (p Point) coordinates() string {
+	return formatFloat(p.X) + " " + formatFloat(p.Y)
+}
+
+func (l LineString) coordinates() string {
+	parts := make([]string, len(l))
+	for i, p := range l {
+		parts[i] = p.coordinates()
+	}
+
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+func (p Polygon) coordinates() string {
+	parts := make([]string, len(p))
+	for i, ring := range p {
+		parts[i] = ring.coordinates()
+	}
+
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+func wkt(name string, parts []string) string {
+	if len(parts) == 0 {
+		return name + " EMPTY"
+	}
+
+	return name + "(" + strings.Join(parts, ",") + ")"
+}
+
+func (p Point) WKT() string {
+	return "POINT(" + p.coordinates() + ")"
+}
+
+func (l LineString) WKT() string {
+	if len(l) == 0 {
+		return "LINESTRING EMPTY"
+	}
+
+	return "LINESTRING" + l.coordinates()
+}
+
+func (p Polygon) WKT() string {
+	if len(p) == 0 {
+		return "POLYGON EMPTY"
+	}
+
+	return "POLYGON" + p.coordinates()
+}
+
+func (m MultiPoint) WKT() string {
+	parts := make([]string, len(m))
+	for i, p := range m {
+		parts[i] = "(" + p.coordinates() + ")"
+	}
+
+	return wkt("MULTIPOINT", parts)
+}
+
+func (m MultiLineString) WKT() string {
+	parts := make([]string, len(m))
+	for i, l := range m {
+		parts[i] = l.coordinates()
+	}
+
+	return wkt("MULTILINESTRING", parts)
+}
+
+func (m MultiPolygon) WKT() string {
+	parts := make([]string, len(m))
+	for i, p := range m {
+		parts[i] = p.coordinates()
+	}
+
+	return wkt("MULTIPOLYGON", parts)
+}
+
+func (c GeometryCollection) WKT() string {
+	parts := make([]string, len(c))
+	for i, g := range c {
+		parts[i] = g.WKT()
+	}
+
+	return wkt("GEOMETRYCOLLECTION", parts)
+}
@@ -0,0 +1,114 @@
+package geometry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Little endian WKB builder
+type testWKB struct {
+	bytes.Buffer
+}
+
+func (w *testWKB) header(geometryType uint32) *testWKB {
+	w.WriteByte(1)
+	binary.Write(w, binary.LittleEndian, geometryType)
+	return w
+}
+
+func (w *testWKB) uint32(v uint32) *testWKB {
+	binary.Write(w, binary.LittleEndian, v)
+	return w
+}
+
+func (w *testWKB) points(coordinates ...float64) *testWKB {
+	for _, c := range coordinates {
+		binary.Write(w, binary.LittleEndian, math.Float64bits(c))
+	}
+	return w
+}
+
+func TestDecode(t *testing.T) {
+	square := func(w *testWKB) *testWKB {
+		return w.uint32(1).uint32(4).points(0, 0, 10, 0, 10, 10, 0, 0)
+	}
+
+	cases := []struct {
+		wkb      *testWKB
+		expected string
+	}{
+		{new(testWKB).header(WKB_POINT).points(1, -2.5), "POINT(1 -2.5)"},
+		{new(testWKB).header(WKB_LINESTRING).uint32(2).points(0, 0, 1, 1), "LINESTRING(0 0,1 1)"},
+		{square(new(testWKB).header(WKB_POLYGON)), "POLYGON((0 0,10 0,10 10,0 0))"},
+		{
+			new(testWKB).header(WKB_MULTIPOINT).uint32(2).
+				header(WKB_POINT).points(1, 2).
+				header(WKB_POINT).points(3, 4),
+			"MULTIPOINT((1 2),(3 4))",
+		},
+		{
+			new(testWKB).header(WKB_MULTILINESTRING).uint32(1).
+				header(WKB_LINESTRING).uint32(2).points(0, 0, 1, 1),
+			"MULTILINESTRING((0 0,1 1))",
+		},
+		{
+			square(new(testWKB).header(WKB_MULTIPOLYGON).uint32(1).header(WKB_POLYGON)),
+			"MULTIPOLYGON(((0 0,10 0,10 10,0 0)))",
+		},
+		{
+			new(testWKB).header(WKB_GEOMETRYCOLLECTION).uint32(2).
+				header(WKB_POINT).points(1, 2).
+				header(WKB_LINESTRING).uint32(2).points(0, 0, 1, 1),
+			"GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(0 0,1 1))",
+		},
+		{new(testWKB).header(WKB_GEOMETRYCOLLECTION).uint32(0), "GEOMETRYCOLLECTION EMPTY"},
+	}
+
+	for _, c := range cases {
+		g, err := Decode(c.wkb.Bytes())
+		assert.NoError(t, err)
+		if assert.NotNil(t, g) {
+			assert.Equal(t, c.expected, g.WKT())
+		}
+	}
+}
+
+func TestDecodeBigEndian(t *testing.T) {
+	b := []byte{0x00, 0x00, 0x00, 0x00, 0x01}
+	b = append(b, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0) // 1
+	b = append(b, 0x40, 0x00, 0, 0, 0, 0, 0, 0) // 2
+
+	g, err := Decode(b)
+	assert.NoError(t, err)
+	assert.Equal(t, Point{X: 1, Y: 2}, g)
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	cases := [][]byte{
+		{},
+		{0x02, 0x01, 0x00, 0x00, 0x00},
+		new(testWKB).header(99).Bytes(),
+		new(testWKB).header(WKB_POINT).points(1).Bytes(),
+		new(testWKB).header(WKB_LINESTRING).uint32(1000000).Bytes(),
+		new(testWKB).header(WKB_MULTIPOINT).uint32(1).header(WKB_LINESTRING).uint32(0).Bytes(),
+		new(testWKB).header(WKB_POINT).points(1, 2, 3).Bytes(),
+	}
+
+	for _, b := range cases {
+		_, err := Decode(b)
+		assert.ErrorIs(t, err, ErrMalformedWKB)
+	}
+}
+
+func TestDecodeMysql(t *testing.T) {
+	b := append([]byte{0xe6, 0x10, 0x00, 0x00}, new(testWKB).header(WKB_POINT).points(1, 2).Bytes()...)
+
+	srid, g, err := DecodeMysql(b)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(4326), srid)
+	assert.Equal(t, "POINT(1 2)", g.WKT())
+}
@@ -0,0 +1,280 @@
+package gtid
+
+// For the text format, see:
+// http://dev.mysql.com/doc/refman/5.6/en/replication-gtids-concepts.html
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Server uuid (SID) that a transaction originated from
+type UUID [16]byte
+
+func ParseUUID(s string) (UUID, error) {
+	var uuid UUID
+
+	b, err := hex.DecodeString(strings.Replace(s, "-", "", -1))
+	if err != nil {
+		return uuid, fmt.Errorf("invalid uuid %q: %v", s, err)
+	}
+
+	if len(b) != len(uuid) {
+		return uuid, fmt.Errorf("invalid uuid %q: expected 16 bytes, got %v", s, len(b))
+	}
+
+	copy(uuid[:], b)
+	return uuid, nil
+}
+
+func (u UUID) String() string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// Inclusive range of transaction numbers (GNOs)
+type Interval struct {
+	Start int64
+	End   int64
+}
+
+func (i Interval) String() string {
+	if i.Start == i.End {
+		return strconv.FormatInt(i.Start, 10)
+	}
+
+	return fmt.Sprintf("%v-%v", i.Start, i.End)
+}
+
+/*
+GTID SETS
+=========
+
+A GTID set is a set of transaction numbers per server uuid,
+written by MySQL as:
+
+3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100:200,
+5a2e7f3c-71ca-11e1-9e33-c80aa9429562:1-3
+
+The intervals of each uuid are kept sorted and merged, so
+two sets with the same transactions always format the same.
+
+*/
+
+type GTIDSet struct {
+	sets map[UUID][]Interval
+}
+
+func NewGTIDSet() *GTIDSet {
+	return &GTIDSet{
+		sets: make(map[UUID][]Interval),
+	}
+}
+
+func ParseGTIDSet(s string) (*GTIDSet, error) {
+	set := NewGTIDSet()
+
+	for _, uuidSet := range strings.Split(s, ",") {
+		uuidSet = strings.TrimSpace(uuidSet)
+		if uuidSet == "" {
+			continue
+		}
+
+		parts := strings.Split(uuidSet, ":")
+
+		uuid, err := ParseUUID(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(parts) == 1 {
+			return nil, fmt.Errorf("invalid gtid set %q: no intervals for %v", s, uuid)
+		}
+
+		for _, part := range parts[1:] {
+			interval, err := parseInterval(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("invalid gtid set %q: %v", s, err)
+			}
+
+			set.AddInterval(uuid, interval)
+		}
+	}
+
+	return set, nil
+}
+
+func parseInterval(s string) (Interval, error) {
+	bounds := strings.SplitN(s, "-", 2)
+
+	start, err := strconv.ParseInt(bounds[0], 10, 64)
+	if err != nil {
+		return Interval{}, err
+	}
+
+	end := start
+	if len(bounds) == 2 {
+		end, err = strconv.ParseInt(bounds[1], 10, 64)
+		if err != nil {
+			return Interval{}, err
+		}
+	}
+
+	if start < 1 || end < start {
+		return Interval{}, fmt.Errorf("invalid interval %q", s)
+	}
+
+	return Interval{Start: start, End: end}, nil
+}
+
+// Sorted by uuid, the same way MySQL prints them
+func (s *GTIDSet) UUIDs() []UUID {
+	uuids := make([]UUID, 0, len(s.sets))
+	for uuid := range s.sets {
+		uuids = append(uuids, uuid)
+	}
+
+	sort.Slice(uuids, func(i, j int) bool {
+		return uuids[i].String() < uuids[j].String()
+	})
+
+	return uuids
+}
+
+func (s *GTIDSet) Intervals(uuid UUID) []Interval {
+	return append([]Interval{}, s.sets[uuid]...)
+}
+
+func (s *GTIDSet) String() string {
+	uuidSets := []string{}
+
+	for _, uuid := range s.UUIDs() {
+		parts := []string{uuid.String()}
+		for _, interval := range s.sets[uuid] {
+			parts = append(parts, interval.String())
+		}
+
+		uuidSets = append(uuidSets, strings.Join(parts, ":"))
+	}
+
+	return strings.Join(uuidSets, ",")
+}
+
+func (s *GTIDSet) IsEmpty() bool {
+	return len(s.sets) == 0
+}
+
+func (s *GTIDSet) Clone() *GTIDSet {
+	clone := NewGTIDSet()
+	for uuid, intervals := range s.sets {
+		clone.sets[uuid] = append([]Interval{}, intervals...)
+	}
+
+	return clone
+}
+
+// Adds a single transaction, e.g. from a GTID_EVENT
+func (s *GTIDSet) Add(uuid UUID, gno int64) {
+	s.AddInterval(uuid, Interval{Start: gno, End: gno})
+}
+
+func (s *GTIDSet) AddInterval(uuid UUID, interval Interval) {
+	s.sets[uuid] = mergeIntervals(append(s.sets[uuid], interval))
+}
+
+func (s *GTIDSet) ContainsGTID(uuid UUID, gno int64) bool {
+	for _, interval := range s.sets[uuid] {
+		if gno >= interval.Start && gno <= interval.End {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Whether every transaction in other is also in s
+func (s *GTIDSet) Contains(other *GTIDSet) bool {
+	return other.Subtract(s).IsEmpty()
+}
+
+func (s *GTIDSet) Equal(other *GTIDSet) bool {
+	return s.Contains(other) && other.Contains(s)
+}
+
+func (s *GTIDSet) Union(other *GTIDSet) *GTIDSet {
+	union := s.Clone()
+
+	for uuid, intervals := range other.sets {
+		union.sets[uuid] = mergeIntervals(append(union.sets[uuid], intervals...))
+	}
+
+	return union
+}
+
+// Transactions in s that are not in other
+func (s *GTIDSet) Subtract(other *GTIDSet) *GTIDSet {
+	difference := NewGTIDSet()
+
+	for uuid, intervals := range s.sets {
+		remaining := subtractIntervals(intervals, other.sets[uuid])
+		if len(remaining) > 0 {
+			difference.sets[uuid] = remaining
+		}
+	}
+
+	return difference
+}
+
+// Sorts and merges overlapping or adjacent intervals
+func mergeIntervals(intervals []Interval) []Interval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].Start < intervals[j].Start
+	})
+
+	merged := []Interval{}
+	for _, interval := range intervals {
+		last := len(merged) - 1
+
+		if last >= 0 && interval.Start <= merged[last].End+1 {
+			if interval.End > merged[last].End {
+				merged[last].End = interval.End
+			}
+
+			continue
+		}
+
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
+
+// Both a and b must be merged
+func subtractIntervals(a, b []Interval) []Interval {
+	result := []Interval{}
+
+	for _, interval := range a {
+		for _, removed := range b {
+			if removed.End < interval.Start || removed.Start > interval.End {
+				continue
+			}
+
+			if removed.Start > interval.Start {
+				result = append(result, Interval{Start: interval.Start, End: removed.Start - 1})
+			}
+
+			interval.Start = removed.End + 1
+			if interval.Start > interval.End {
+				break
+			}
+		}
+
+		if interval.Start <= interval.End {
+			result = append(result, interval)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,70 @@
+package gtid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	uuidA string = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	uuidB string = "5a2e7f3c-71ca-11e1-9e33-c80aa9429562"
+)
+
+func mustParse(t *testing.T, s string) *GTIDSet {
+	set, err := ParseGTIDSet(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return set
+}
+
+func TestParseGTIDSet(t *testing.T) {
+	set := mustParse(t, uuidB+":1-3,\n"+uuidA+":200:1-100:101")
+	assert.Equal(t, uuidA+":1-101:200,"+uuidB+":1-3", set.String())
+
+	set = mustParse(t, "3E11FA47-71CA-11E1-9E33-C80AA9429562:5")
+	assert.Equal(t, uuidA+":5", set.String())
+
+	assert.True(t, mustParse(t, "").IsEmpty())
+
+	for _, invalid := range []string{uuidA, uuidA + ":0", uuidA + ":5-3", "zz:1", uuidA + ":a"} {
+		_, err := ParseGTIDSet(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestGTIDSetUnion(t *testing.T) {
+	union := mustParse(t, uuidA+":1-5").Union(mustParse(t, uuidA+":6-10:20,"+uuidB+":1"))
+	assert.Equal(t, uuidA+":1-10:20,"+uuidB+":1", union.String())
+}
+
+func TestGTIDSetSubtract(t *testing.T) {
+	difference := mustParse(t, uuidA+":1-100,"+uuidB+":1-3").Subtract(mustParse(t, uuidA+":10-20:50,"+uuidB+":1-3"))
+	assert.Equal(t, uuidA+":1-9:21-49:51-100", difference.String())
+}
+
+func TestGTIDSetContains(t *testing.T) {
+	set := mustParse(t, uuidA+":1-100:200")
+
+	assert.True(t, set.Contains(mustParse(t, uuidA+":5-10:200")))
+	assert.False(t, set.Contains(mustParse(t, uuidA+":100-101")))
+	assert.False(t, set.Contains(mustParse(t, uuidB+":1")))
+
+	uuid, _ := ParseUUID(uuidA)
+	assert.True(t, set.ContainsGTID(uuid, 200))
+	assert.False(t, set.ContainsGTID(uuid, 150))
+}
+
+func TestGTIDSetAdd(t *testing.T) {
+	set := NewGTIDSet()
+	uuid, _ := ParseUUID(uuidA)
+
+	set.Add(uuid, 1)
+	set.Add(uuid, 3)
+	assert.Equal(t, uuidA+":1:3", set.String())
+
+	set.Add(uuid, 2)
+	assert.Equal(t, uuidA+":1-3", set.String())
+}
@@ -0,0 +1,173 @@
+package binlog
+
+import (
+	. "github.com/granicus/mysql-binlog-go/deserialization"
+	"github.com/granicus/mysql-binlog-go/gtid"
+)
+
+// Used for both GTID_EVENT and ANONYMOUS_GTID_EVENT
+// (anonymous ones have a zero SID and GNO)
+type GtidEvent struct {
+	Anonymous      bool
+	CommitFlag     bool
+	SID            gtid.UUID
+	GNO            int64
+	LastCommitted  int64
+	SequenceNumber int64
+}
+
+/*
+GTID EVENT DATA
+===============
+
+Fixed:
+1 byte   = commit flag
+16 bytes = SID (server uuid)
+8 bytes  = GNO (transaction number)
+
+MySQL 5.7+ only (logical clock, used by parallel replication):
+1 byte   = logical timestamp type code (2)
+8 bytes  = last committed
+8 bytes  = sequence number
+
+Anything after that (MySQL 8 commit timestamps, transaction
+length, server versions) is skipped.
+
+*/
+
+const LOGICAL_TIMESTAMP_TYPECODE byte = 2
+
+func (b *Binlog) DeserializeGtidEvent(header *EventHeader) (EventData, error) {
+	e := new(GtidEvent)
+	e.Anonymous = header.Type == ANONYMOUS_GTID_EVENT
+
+	commitFlag, err := ReadByte(b.reader)
+	if err != nil {
+		return nil, err
+	}
+	e.CommitFlag = commitFlag != 0
+
+	sid, err := ReadBytes(b.reader, len(e.SID))
+	if err != nil {
+		return nil, err
+	}
+	copy(e.SID[:], sid)
+
+	e.GNO, err = ReadInt64(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := b.remainingEventLength(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if remaining < 1+8+8 {
+		return e, nil
+	}
+
+	typeCode, err := ReadByte(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if typeCode != LOGICAL_TIMESTAMP_TYPECODE {
+		return e, nil
+	}
+
+	e.LastCommitted, err = ReadInt64(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	e.SequenceNumber, err = ReadInt64(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Formatted the way MySQL does, e.g. 3e11fa47-71ca-11e1-9e33-c80aa9429562:23
+func (e *GtidEvent) String() string {
+	if e.Anonymous {
+		return "ANONYMOUS"
+	}
+
+	return e.SID.String() + ":" + gtid.Interval{Start: e.GNO, End: e.GNO}.String()
+}
+
+// Records the transaction in a set of executed GTIDs
+func (e *GtidEvent) AddTo(set *gtid.GTIDSet) {
+	if !e.Anonymous {
+		set.Add(e.SID, e.GNO)
+	}
+}
+
+// Every GTID executed before the current file
+type PreviousGtidsEvent struct {
+	Set *gtid.GTIDSet
+}
+
+/*
+PREVIOUS GTIDS EVENT DATA
+=========================
+
+Let:
+S = number of SIDs
+I = number of intervals for a SID
+
+8 bytes = S
+S * (
+	16 bytes = SID
+	8 bytes  = I
+	I * (
+		8 bytes = start
+		8 bytes = end (exclusive)
+	)
+)
+
+*/
+
+func (b *Binlog) DeserializePreviousGtidsEvent(header *EventHeader) (EventData, error) {
+	e := &PreviousGtidsEvent{
+		Set: gtid.NewGTIDSet(),
+	}
+
+	sidCount, err := ReadUint64(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := uint64(0); i < sidCount; i++ {
+		var sid gtid.UUID
+
+		sidBytes, err := ReadBytes(b.reader, len(sid))
+		if err != nil {
+			return nil, err
+		}
+		copy(sid[:], sidBytes)
+
+		intervalCount, err := ReadUint64(b.reader)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := uint64(0); j < intervalCount; j++ {
+			start, err := ReadInt64(b.reader)
+			if err != nil {
+				return nil, err
+			}
+
+			end, err := ReadInt64(b.reader)
+			if err != nil {
+				return nil, err
+			}
+
+			e.Set.AddInterval(sid, gtid.Interval{Start: start, End: end - 1})
+		}
+	}
+
+	return e, nil
+}
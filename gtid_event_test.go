@@ -0,0 +1,56 @@
+package binlog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/granicus/mysql-binlog-go/gtid"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSID = bytes.Repeat([]byte{0xab}, 16)
+
+func TestDeserializeGtidEvent(t *testing.T) {
+	body := []byte{0x01}
+	body = append(body, testSID...)
+	body = append(body, 0x17, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	body = append(body, LOGICAL_TIMESTAMP_TYPECODE)
+	body = append(body, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	body = append(body, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+
+	b, header := newTestBinlog(GTID_EVENT, body)
+
+	data, err := b.DeserializeGtidEvent(header)
+	assert.NoError(t, err)
+
+	e := data.(*GtidEvent)
+	assert.True(t, e.CommitFlag)
+	assert.Equal(t, int64(23), e.GNO)
+	assert.Equal(t, int64(5), e.LastCommitted)
+	assert.Equal(t, int64(6), e.SequenceNumber)
+	assert.Equal(t, "abababab-abab-abab-abab-abababababab:23", e.String())
+
+	set := gtid.NewGTIDSet()
+	e.AddTo(set)
+	assert.True(t, set.ContainsGTID(e.SID, 23))
+}
+
+func TestDeserializePreviousGtidsEvent(t *testing.T) {
+	body := []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	body = append(body, testSID...)
+	body = append(body,
+		0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x65, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xc8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xc9, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	)
+
+	b, header := newTestBinlog(PREVIOUS_GTIDS_EVENT, body)
+
+	data, err := b.DeserializePreviousGtidsEvent(header)
+	assert.NoError(t, err)
+
+	e := data.(*PreviousGtidsEvent)
+	assert.Equal(t, "abababab-abab-abab-abab-abababababab:1-100:200", e.Set.String())
+}
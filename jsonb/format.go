@@ -0,0 +1,98 @@
+package jsonb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formats a value returned by Decode the way MySQL prints JSON,
+// e.g. {"a": [1, 2.5, "x"], "bc": null}. Object keys are ordered
+// by length and then bytewise, which is also how they are stored.
+func Format(value interface{}) string {
+	buf := new(bytes.Buffer)
+	format(buf, value)
+	return buf.String()
+}
+
+func format(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+
+	case uint64:
+		buf.WriteString(strconv.FormatUint(v, 10))
+
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEnN") {
+			s += ".0"
+		}
+
+		buf.WriteString(s)
+
+	case json.Number:
+		buf.WriteString(v.String())
+
+	case string:
+		formatString(buf, v)
+
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+
+			format(buf, element)
+		}
+		buf.WriteByte(']')
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) < len(keys[j])
+			}
+
+			return keys[i] < keys[j]
+		})
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+
+			formatString(buf, key)
+			buf.WriteString(": ")
+			format(buf, v[key])
+		}
+		buf.WriteByte('}')
+
+	default:
+		formatString(buf, fmt.Sprint(v))
+	}
+}
+
+func formatString(buf *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+
+	// Encode adds a newline
+	buf.Truncate(buf.Len() - 1)
+}
@@ -0,0 +1,427 @@
+package jsonb
+
+// Based on sql/json_binary.h in the MySQL 5.7 source:
+// https://github.com/mysql/mysql-server/blob/5.7/sql/json_binary.h
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/granicus/mysql-binlog-go/deserialization"
+)
+
+/*
+MYSQL BINARY JSON
+=================
+
+Every value starts with a 1 byte type:
+
+0x00 = small object
+0x01 = large object
+0x02 = small array
+0x03 = large array
+0x04 = literal (null, true, false)
+0x05 = int16
+0x06 = uint16
+0x07 = int32
+0x08 = uint32
+0x09 = int64
+0x0a = uint64
+0x0b = double
+0x0c = utf8mb4 string
+0x0f = opaque (any other MySQL type, e.g. DECIMAL or DATETIME)
+
+Let:
+O = offset size (2 bytes for small, 4 bytes for large)
+N = element count
+
+Objects and arrays:
+O bytes     = N
+O bytes     = size of the object/array in bytes
+N * (                          (objects only)
+	O bytes = key offset
+	2 bytes = key length
+)
+N * (
+	1 byte  = value type
+	O bytes = value offset, or the value itself when it fits
+)
+keys and values
+
+Offsets are relative to the byte after the type. Literals, int16 and
+uint16 are always stored in the value entry, int32 and uint32 are
+stored there in large objects/arrays only.
+
+Strings and opaque values have their length stored as a variable
+length integer: 7 bits per byte, least significant first, with the
+highest bit set on every byte except the last one.
+
+Opaque:
+1 byte  = MySQL column type
+V bytes = length (variable length integer)
+L bytes = data
+
+*/
+
+const (
+	JSONB_TYPE_SMALL_OBJECT byte = 0x0
+	JSONB_TYPE_LARGE_OBJECT byte = 0x1
+	JSONB_TYPE_SMALL_ARRAY  byte = 0x2
+	JSONB_TYPE_LARGE_ARRAY  byte = 0x3
+	JSONB_TYPE_LITERAL      byte = 0x4
+	JSONB_TYPE_INT16        byte = 0x5
+	JSONB_TYPE_UINT16       byte = 0x6
+	JSONB_TYPE_INT32        byte = 0x7
+	JSONB_TYPE_UINT32       byte = 0x8
+	JSONB_TYPE_INT64        byte = 0x9
+	JSONB_TYPE_UINT64       byte = 0xa
+	JSONB_TYPE_DOUBLE       byte = 0xb
+	JSONB_TYPE_STRING       byte = 0xc
+	JSONB_TYPE_OPAQUE       byte = 0xf
+)
+
+const (
+	JSONB_NULL_LITERAL  byte = 0x0
+	JSONB_TRUE_LITERAL  byte = 0x1
+	JSONB_FALSE_LITERAL byte = 0x2
+)
+
+// MySQL column types that show up in opaque values
+// (duplicated from the main package to avoid an import cycle)
+const (
+	mysqlTypeNewDecimal byte = 246
+	mysqlTypeDate       byte = 10
+	mysqlTypeTime       byte = 11
+	mysqlTypeDatetime   byte = 12
+	mysqlTypeTimestamp  byte = 7
+)
+
+var ErrMalformedJSON = errors.New("malformed binary json")
+
+/*
+Decode turns a binary JSON value into:
+
+object        = map[string]interface{}
+array         = []interface{}
+null          = nil
+true/false    = bool
+int16/32/64   = int64
+uint16/32/64  = uint64
+double        = float64
+string        = string
+DECIMAL       = json.Number (no precision is lost)
+DATE/TIME/... = string, formatted the way MySQL prints them
+other opaque  = string, "base64:type<N>:<data>" like MySQL prints them
+
+An empty value (which MySQL writes for a JSON null in some
+versions) decodes to nil.
+*/
+func Decode(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	return decodeValue(data[0], data[1:])
+}
+
+func malformed(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %v", ErrMalformedJSON, fmt.Sprintf(format, args...))
+}
+
+func decodeValue(valueType byte, data []byte) (interface{}, error) {
+	switch valueType {
+	case JSONB_TYPE_SMALL_OBJECT:
+		return decodeComposite(data, true, false)
+	case JSONB_TYPE_LARGE_OBJECT:
+		return decodeComposite(data, true, true)
+	case JSONB_TYPE_SMALL_ARRAY:
+		return decodeComposite(data, false, false)
+	case JSONB_TYPE_LARGE_ARRAY:
+		return decodeComposite(data, false, true)
+	case JSONB_TYPE_LITERAL:
+		return decodeLiteral(data)
+	case JSONB_TYPE_STRING:
+		return decodeString(data)
+	case JSONB_TYPE_OPAQUE:
+		return decodeOpaque(data)
+	}
+
+	return decodeNumber(valueType, data)
+}
+
+func decodeLiteral(data []byte) (interface{}, error) {
+	if len(data) < 1 {
+		return nil, malformed("missing literal")
+	}
+
+	switch data[0] {
+	case JSONB_NULL_LITERAL:
+		return nil, nil
+	case JSONB_TRUE_LITERAL:
+		return true, nil
+	case JSONB_FALSE_LITERAL:
+		return false, nil
+	}
+
+	return nil, malformed("unknown literal %v", data[0])
+}
+
+func decodeNumber(valueType byte, data []byte) (interface{}, error) {
+	size := 0
+
+	switch valueType {
+	case JSONB_TYPE_INT16, JSONB_TYPE_UINT16:
+		size = 2
+	case JSONB_TYPE_INT32, JSONB_TYPE_UINT32:
+		size = 4
+	case JSONB_TYPE_INT64, JSONB_TYPE_UINT64, JSONB_TYPE_DOUBLE:
+		size = 8
+	default:
+		return nil, malformed("unknown value type %v", valueType)
+	}
+
+	if len(data) < size {
+		return nil, malformed("value of type %v is truncated", valueType)
+	}
+
+	switch valueType {
+	case JSONB_TYPE_INT16:
+		return int64(int16(binary.LittleEndian.Uint16(data))), nil
+	case JSONB_TYPE_UINT16:
+		return uint64(binary.LittleEndian.Uint16(data)), nil
+	case JSONB_TYPE_INT32:
+		return int64(int32(binary.LittleEndian.Uint32(data))), nil
+	case JSONB_TYPE_UINT32:
+		return uint64(binary.LittleEndian.Uint32(data)), nil
+	case JSONB_TYPE_INT64:
+		return int64(binary.LittleEndian.Uint64(data)), nil
+	case JSONB_TYPE_UINT64:
+		return binary.LittleEndian.Uint64(data), nil
+	}
+
+	return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+}
+
+// Returns the length and how many bytes it took up
+func readVariableLength(data []byte) (int, int, error) {
+	length := 0
+
+	for i := 0; i < len(data) && i < 5; i++ {
+		length |= int(data[i]&0x7f) << uint(7*i)
+
+		if data[i]&0x80 == 0 {
+			return length, i + 1, nil
+		}
+	}
+
+	return 0, 0, malformed("invalid variable length integer")
+}
+
+func readVariableLengthData(data []byte) ([]byte, error) {
+	length, n, err := readVariableLength(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < n+length {
+		return nil, malformed("data of length %v is truncated", length)
+	}
+
+	return data[n : n+length], nil
+}
+
+func decodeString(data []byte) (interface{}, error) {
+	b, err := readVariableLengthData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func decodeComposite(data []byte, isObject, isLarge bool) (interface{}, error) {
+	offsetSize := 2
+	if isLarge {
+		offsetSize = 4
+	}
+
+	readOffset := func(at int) (int, error) {
+		if at < 0 || at+offsetSize > len(data) {
+			return 0, malformed("offset %v out of range", at)
+		}
+
+		if isLarge {
+			return int(binary.LittleEndian.Uint32(data[at:])), nil
+		}
+
+		return int(binary.LittleEndian.Uint16(data[at:])), nil
+	}
+
+	count, err := readOffset(0)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := readOffset(offsetSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > len(data) {
+		return nil, malformed("size %v larger than the %v bytes available", size, len(data))
+	}
+	data = data[:size]
+
+	keyEntrySize := offsetSize + 2
+	valueEntrySize := 1 + offsetSize
+
+	keyEntriesStart := 2 * offsetSize
+	valueEntriesStart := keyEntriesStart
+	if isObject {
+		valueEntriesStart += count * keyEntrySize
+	}
+
+	if valueEntriesStart+count*valueEntrySize > len(data) {
+		return nil, malformed("%v entries do not fit in %v bytes", count, len(data))
+	}
+
+	values := make([]interface{}, count)
+	for i := range values {
+		entry := valueEntriesStart + i*valueEntrySize
+		valueType := data[entry]
+
+		if isInlined(valueType, isLarge) {
+			values[i], err = decodeValue(valueType, data[entry+1:entry+1+offsetSize])
+		} else {
+			var offset int
+			offset, err = readOffset(entry + 1)
+			if err == nil {
+				if offset >= len(data) {
+					return nil, malformed("value offset %v out of range", offset)
+				}
+
+				values[i], err = decodeValue(valueType, data[offset:])
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !isObject {
+		return values, nil
+	}
+
+	object := make(map[string]interface{}, count)
+	for i := 0; i < count; i++ {
+		entry := keyEntriesStart + i*keyEntrySize
+
+		keyOffset, err := readOffset(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		keyLength := int(binary.LittleEndian.Uint16(data[entry+offsetSize:]))
+		if keyOffset+keyLength > len(data) {
+			return nil, malformed("key offset %v out of range", keyOffset)
+		}
+
+		object[string(data[keyOffset:keyOffset+keyLength])] = values[i]
+	}
+
+	return object, nil
+}
+
+func isInlined(valueType byte, isLarge bool) bool {
+	switch valueType {
+	case JSONB_TYPE_LITERAL, JSONB_TYPE_INT16, JSONB_TYPE_UINT16:
+		return true
+	case JSONB_TYPE_INT32, JSONB_TYPE_UINT32:
+		return isLarge
+	}
+
+	return false
+}
+
+func decodeOpaque(data []byte) (interface{}, error) {
+	if len(data) < 1 {
+		return nil, malformed("missing opaque type")
+	}
+
+	mysqlType := data[0]
+	b, err := readVariableLengthData(data[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch mysqlType {
+	case mysqlTypeNewDecimal:
+		if len(b) < 2 {
+			return nil, malformed("decimal is truncated")
+		}
+
+		value, err := deserialization.ReadDecimalValue(bytes.NewReader(b[2:]), int(b[0]), int(b[1]))
+		if err != nil {
+			return nil, malformed("decimal: %v", err)
+		}
+
+		return json.Number(value), nil
+
+	case mysqlTypeDate, mysqlTypeTime, mysqlTypeDatetime, mysqlTypeTimestamp:
+		if len(b) < 8 {
+			return nil, malformed("temporal value is truncated")
+		}
+
+		return formatPackedTemporal(mysqlType, int64(binary.LittleEndian.Uint64(b))), nil
+	}
+
+	return fmt.Sprintf("base64:type%v:%v", mysqlType, base64.StdEncoding.EncodeToString(b)), nil
+}
+
+/*
+Temporal values inside JSON are stored in MySQL's in-memory
+"packed" int64 format (sign applied to the whole value):
+
+DATE/DATETIME/TIMESTAMP:
+bits 24-63 = ((year * 13 + month) << 5 | day) << 17 | hour << 12 | minute << 6 | second
+bits 0-23  = microseconds
+
+TIME:
+bits 24-63 = hour << 12 | minute << 6 | second
+bits 0-23  = microseconds
+*/
+func formatPackedTemporal(mysqlType byte, packed int64) string {
+	sign := ""
+	if packed < 0 {
+		sign = "-"
+		packed = -packed
+	}
+
+	microseconds := packed % (1 << 24)
+	value := packed >> 24
+
+	if mysqlType == mysqlTypeTime {
+		hour := (value >> 12) % (1 << 10)
+		minute := (value >> 6) % (1 << 6)
+		second := value % (1 << 6)
+
+		return fmt.Sprintf("%v%02d:%02d:%02d.%06d", sign, hour, minute, second, microseconds)
+	}
+
+	ymd := value >> 17
+	yearMonth := ymd >> 5
+	hms := value % (1 << 17)
+
+	date := fmt.Sprintf("%04d-%02d-%02d", yearMonth/13, yearMonth%13, ymd%(1<<5))
+	if mysqlType == mysqlTypeDate {
+		return date
+	}
+
+	return fmt.Sprintf("%v %02d:%02d:%02d.%06d", date, hms>>12, (hms>>6)%(1<<6), hms%(1<<6), microseconds)
+}
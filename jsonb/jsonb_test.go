@@ -0,0 +1,96 @@
+package jsonb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeAndFormat(t *testing.T, data []byte) (interface{}, string) {
+	value, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return value, Format(value)
+}
+
+func TestDecodeScalars(t *testing.T) {
+	cases := []struct {
+		data     []byte
+		expected interface{}
+		text     string
+	}{
+		{[]byte{JSONB_TYPE_LITERAL, JSONB_NULL_LITERAL}, nil, "null"},
+		{[]byte{JSONB_TYPE_LITERAL, JSONB_TRUE_LITERAL}, true, "true"},
+		{[]byte{JSONB_TYPE_INT16, 0xfe, 0xff}, int64(-2), "-2"},
+		{[]byte{JSONB_TYPE_UINT64, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, uint64(18446744073709551615), "18446744073709551615"},
+		{[]byte{JSONB_TYPE_DOUBLE, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x40}, 2.5, "2.5"},
+		{[]byte{JSONB_TYPE_DOUBLE, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x40}, 3.0, "3.0"},
+		{[]byte{JSONB_TYPE_STRING, 0x05, 'h', 'e', '"', 'l', 'o'}, "he\"lo", `"he\"lo"`},
+		{
+			// DECIMAL(4, 2) 12.34
+			[]byte{JSONB_TYPE_OPAQUE, mysqlTypeNewDecimal, 0x04, 0x04, 0x02, 0x8c, 0x22},
+			json.Number("12.34"), "12.34",
+		},
+		{
+			// DATETIME 2015-01-15 23:24:25.000010
+			[]byte{JSONB_TYPE_OPAQUE, mysqlTypeDatetime, 0x08, 0x0a, 0x00, 0x00, 0x19, 0x76, 0x1f, 0x95, 0x19},
+			"2015-01-15 23:24:25.000010", `"2015-01-15 23:24:25.000010"`,
+		},
+	}
+
+	for _, c := range cases {
+		value, text := decodeAndFormat(t, c.data)
+		assert.Equal(t, c.expected, value)
+		assert.Equal(t, c.text, text)
+	}
+}
+
+func TestDecodeSmallObject(t *testing.T) {
+	// {"a": [1, "x"], "bc": true}
+	data := []byte{
+		JSONB_TYPE_SMALL_OBJECT,
+		0x02, 0x00, // count
+		0x21, 0x00, // size
+		0x12, 0x00, 0x01, 0x00, // key "a"
+		0x13, 0x00, 0x02, 0x00, // key "bc"
+		JSONB_TYPE_SMALL_ARRAY, 0x15, 0x00,
+		JSONB_TYPE_LITERAL, JSONB_TRUE_LITERAL, 0x00,
+		'a', 'b', 'c',
+		// array at offset 0x15
+		0x02, 0x00, // count
+		0x0c, 0x00, // size
+		JSONB_TYPE_INT16, 0x01, 0x00,
+		JSONB_TYPE_STRING, 0x0a, 0x00,
+		0x01, 'x',
+	}
+
+	value, text := decodeAndFormat(t, data)
+	assert.Equal(t, map[string]interface{}{
+		"a":  []interface{}{int64(1), "x"},
+		"bc": true,
+	}, value)
+	assert.Equal(t, `{"a": [1, "x"], "bc": true}`, text)
+}
+
+func TestDecodeLargeArray(t *testing.T) {
+	// [70000, -1]
+	data := []byte{
+		JSONB_TYPE_LARGE_ARRAY,
+		0x02, 0x00, 0x00, 0x00, // count
+		0x12, 0x00, 0x00, 0x00, // size
+		JSONB_TYPE_UINT32, 0x70, 0x11, 0x01, 0x00,
+		JSONB_TYPE_INT32, 0xff, 0xff, 0xff, 0xff,
+	}
+
+	value, text := decodeAndFormat(t, data)
+	assert.Equal(t, []interface{}{uint64(70000), int64(-1)}, value)
+	assert.Equal(t, "[70000, -1]", text)
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	_, err := Decode([]byte{JSONB_TYPE_SMALL_ARRAY, 0x05, 0x00, 0xff, 0x00})
+	assert.ErrorIs(t, err, ErrMalformedJSON)
+}
@@ -163,6 +163,8 @@ const (
 	MYSQL_TYPE_TIME_V2
 )
 
+const MYSQL_TYPE_JSON MysqlType = 245 // MySQL 5.7+
+
 const (
 	MYSQL_TYPE_NEWDECIMAL  MysqlType = 246 + iota
 	MYSQL_TYPE_ENUM                  // Does not appear in binlog
@@ -218,6 +220,8 @@ func (mt MysqlType) String() string {
 		return "MYSQL_TYPE_DATETIME_V2"
 	case MYSQL_TYPE_TIME_V2:
 		return "MYSQL_TYPE_TIME_V2"
+	case MYSQL_TYPE_JSON:
+		return "MYSQL_TYPE_JSON"
 	case MYSQL_TYPE_NEWDECIMAL:
 		return "MYSQL_TYPE_NEWDECIMAL"
 	case MYSQL_TYPE_ENUM:
@@ -0,0 +1,140 @@
+package binlog
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+/*
+NETWORK STREAMS
+===============
+
+After COM_BINLOG_DUMP, a replication connection sends one packet
+per event:
+
+0x00 + event  = the next event
+0xfe          = end of the stream (EOF packet, non-blocking dumps only)
+0xff + error  = the server gave up:
+	2 bytes = error code
+	1 byte  = '#'
+	5 bytes = SQL state
+	rest    = message
+
+The stream starts with a ROTATE_EVENT (naming the file it starts
+in) and a FORMAT_DESCRIPTION_EVENT. Event positions are offsets
+in the stream, not in the server's files; use the event headers
+(NextPosition) and ROTATE_EVENTs for those.
+
+*/
+
+// A source of MySQL protocol packets, e.g. connector.PacketListener
+type PacketReader interface {
+	Read() ([]byte, error)
+}
+
+type ServerError struct {
+	Code     uint16
+	SQLState string
+	Message  string
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server error %v (%v): %v", e.Code, e.SQLState, e.Message)
+}
+
+func parseServerError(packet []byte) *ServerError {
+	e := &ServerError{}
+
+	if len(packet) >= 3 {
+		e.Code = binary.LittleEndian.Uint16(packet[1:3])
+		packet = packet[3:]
+	}
+
+	if len(packet) >= 6 && packet[0] == '#' {
+		e.SQLState = string(packet[1:6])
+		packet = packet[6:]
+	}
+
+	e.Message = string(packet)
+	return e
+}
+
+type NetworkBinlog struct {
+	Binlog
+	packets PacketReader
+	buffer  *AppendableBuffer
+	read    backgroundReader
+	done    bool
+
+	// See SetRetention
+	retention int64
+}
+
+// Sending COM_BINLOG_DUMP is up to the caller, packets should be
+// the connection right after it
+func NewNetworkBinlog(packets PacketReader) *NetworkBinlog {
+	n := &NetworkBinlog{
+		packets: packets,
+		buffer:  NewAppendableBuffer([]byte{}),
+
+		retention: DEFAULT_RETENTION,
+	}
+
+	n.TableMapCollection = make(map[uint64]*TableMapEvent)
+	n.formatDescription = defaultFormatDescription()
+	n.bytesLength = -1
+	n.events = []*Event{}
+	n.reader = n.buffer
+
+	return n
+}
+
+// How many bytes of events to hold on to after Next has returned
+// them (DEFAULT_RETENTION unless set). A negative retention keeps
+// every event.
+func (n *NetworkBinlog) SetRetention(retention int64) {
+	n.retention = retention
+}
+
+// Waits for the next event until ctx is done. Returns io.EOF once
+// the server ends the stream.
+func (n *NetworkBinlog) Next(ctx context.Context) (*Event, error) {
+	n.releaseEvents(n.buffer, n.retention)
+
+	for {
+		event, err := n.Binlog.Next(ctx)
+		if err != io.EOF {
+			return event, err
+		}
+
+		if n.done {
+			return nil, io.EOF
+		}
+
+		packet, err := n.read.read(ctx, n.packets.Read)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(packet) == 0 {
+			return nil, fmt.Errorf("%w: empty packet", ErrMalformedEvent)
+		}
+
+		switch packet[0] {
+		case 0x00:
+			n.buffer.Append(packet[1:])
+
+		case 0xfe:
+			n.done = true
+
+		case 0xff:
+			n.done = true
+			return nil, parseServerError(packet)
+
+		default:
+			return nil, fmt.Errorf("%w: unexpected packet type 0x%02x", ErrMalformedEvent, packet[0])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package binlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPacketReader struct {
+	packets [][]byte
+	block   chan struct{}
+}
+
+func (r *testPacketReader) Read() ([]byte, error) {
+	if len(r.packets) == 0 {
+		<-r.block
+		return nil, io.ErrClosedPipe
+	}
+
+	packet := r.packets[0]
+	r.packets = r.packets[1:]
+	return packet, nil
+}
+
+// Event packets for the events of log (without the magic bytes)
+func testEventPackets(log []byte) [][]byte {
+	packets := [][]byte{}
+	log = log[MAGIC_BYTES_LENGTH:]
+
+	for len(log) > 0 {
+		header, _ := ReadEventHeader(bytes.NewReader(log))
+		packets = append(packets, append([]byte{0x00}, log[:header.Length]...))
+		log = log[header.Length:]
+	}
+
+	return packets
+}
+
+func TestNetworkBinlog(t *testing.T) {
+	packets := append(testEventPackets(testChecksummedBinlog()), []byte{0xfe, 0x00, 0x00, 0x02, 0x00})
+	n := NewNetworkBinlog(&testPacketReader{packets: packets})
+
+	event, err := n.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, FORMAT_DESCRIPTION_EVENT, event.Type())
+	assert.Equal(t, int64(0), event.Position())
+
+	event, err = n.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ROTATE_EVENT, event.Type())
+
+	data, err := event.Data()
+	assert.NoError(t, err)
+	assert.Equal(t, "mysql-bin.000002", data.(*RotateEvent).NextFile)
+
+	_, err = n.Next(context.Background())
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestNetworkBinlogServerError(t *testing.T) {
+	packet := []byte{0xff, 0x7c, 0x04, '#', 'H', 'Y', '0', '0', '0'}
+	packet = append(packet, []byte("Could not find first log file name in binary log index file")...)
+
+	n := NewNetworkBinlog(&testPacketReader{packets: [][]byte{packet}})
+
+	_, err := n.Next(context.Background())
+
+	var serverErr *ServerError
+	assert.True(t, errors.As(err, &serverErr))
+	assert.Equal(t, uint16(1148), serverErr.Code)
+	assert.Equal(t, "HY000", serverErr.SQLState)
+	assert.Equal(t, "Could not find first log file name in binary log index file", serverErr.Message)
+}
+
+func TestNetworkBinlogCancelled(t *testing.T) {
+	packets := &testPacketReader{block: make(chan struct{})}
+	defer close(packets.block)
+
+	n := NewNetworkBinlog(packets)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := n.Next(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestNetworkBinlogRetention(t *testing.T) {
+	packets := testEventPackets(testChecksummedBinlog())
+	rotate := packets[1]
+	for i := 0; i < 100; i++ {
+		packets = append(packets, rotate)
+	}
+
+	n := NewNetworkBinlog(&testPacketReader{packets: packets})
+	n.SetRetention(int64(len(rotate)))
+
+	formatDescription, err := n.Next(context.Background())
+	assert.NoError(t, err)
+
+	released, err := n.Next(context.Background())
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		_, err = n.Next(context.Background())
+		assert.NoError(t, err)
+	}
+
+	// Decoded when it was read
+	_, err = formatDescription.Data()
+	assert.NoError(t, err)
+
+	_, err = released.Data()
+	assert.ErrorIs(t, err, ErrEventReleased)
+
+	assert.LessOrEqual(t, n.buffer.Length(), 3*len(rotate))
+	assert.LessOrEqual(t, len(n.Events()), 3)
+}
+
+func TestNetworkBinlogRetentionKeepsTransaction(t *testing.T) {
+	packets := testEventPackets(testBinlogPreamble())
+
+	log := []byte{}
+	events := [][]byte{serializeTestEvent(QUERY_EVENT, 0, 0, testQueryEventBody("BEGIN"))}
+	for i := 0; i < 50; i++ {
+		// Not decoded while the transaction is collected, like rows events
+		events = append(events, serializeTestEvent(ROWS_QUERY_EVENT, 0, 0, []byte("\x18INSERT INTO t VALUES (1)")))
+	}
+	events = append(events, serializeTestEvent(XID_EVENT, 0, 0, []byte{0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}))
+
+	for _, event := range events {
+		packets = append(packets, append([]byte{0x00}, event...))
+		log = append(log, event...)
+	}
+
+	n := NewNetworkBinlog(&testPacketReader{packets: packets})
+	n.SetRetention(int64(len(events[1])))
+
+	transaction, err := n.Transactions().Next(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, transaction.Events, len(events))
+	assert.Greater(t, len(log), 10*len(events[1]))
+
+	for _, event := range transaction.Events {
+		_, err = event.Data()
+		assert.NoError(t, err)
+	}
+}
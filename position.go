@@ -0,0 +1,73 @@
+package binlog
+
+import (
+	"fmt"
+	"io"
+)
+
+/*
+POSITIONS
+=========
+
+A position is what SHOW MASTER STATUS and CHANGE MASTER TO use: the
+name of a binlog file and the offset of an event in it. Offset 4
+(right after the magic bytes) is the start of the file.
+
+Opening a source at a position reads the events before it without
+keeping them, so the FORMAT_DESCRIPTION_EVENT and the table maps
+the first events need are known, and then Next carries on from
+the event at the offset. An offset that isn't the start of an event
+is an ErrInvalidPosition.
+
+*/
+
+type Position struct {
+	File   string
+	Offset int64
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%v:%v", p.File, p.Offset)
+}
+
+// Reads the events before offset and drops them, keeping what they
+// leave behind (format description, table maps). more is called
+// when the reader runs out of data, nil if there is no more.
+func (b *Binlog) skipTo(offset int64, more func() error) error {
+	if offset == int64(MAGIC_BYTES_LENGTH) && b.nextEventPosition > offset {
+		// Already past the FORMAT_DESCRIPTION_EVENT at the start
+		return nil
+	}
+
+	for b.nextEventPosition < offset {
+		eventStart := b.nextEventPosition
+
+		_, err := b.readEvent()
+		if err == io.EOF && more != nil {
+			err = more()
+			if err == nil {
+				continue
+			}
+		}
+
+		if err == io.EOF {
+			return fmt.Errorf("%w: offset %v is past the end of the file", ErrInvalidPosition, offset)
+		}
+		if err != nil {
+			return err
+		}
+
+		if b.nextEventPosition > offset {
+			return fmt.Errorf("%w: offset %v is inside the event at %v", ErrInvalidPosition, offset, eventStart)
+		}
+
+		b.events = b.events[:0]
+		b.nextEventIndex = 0
+	}
+
+	if b.nextEventPosition != offset {
+		return fmt.Errorf("%w: offset %v is before the first event", ErrInvalidPosition, offset)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package binlog
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns the file and the offset of its last event
+func testPositionedBinlog() ([]byte, int64) {
+	tableMapBody := []byte{
+		0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, // table id
+		0x00, 0x00, // reserved
+		0x02, 'd', 'b', 0x00,
+		0x01, 't', 0x00,
+		0x01, // number of columns
+		byte(MYSQL_TYPE_LONG),
+		0x00, // metadata length
+		0x01, // can be null
+	}
+
+	log := testBinlogPreamble()
+	log = append(log, serializeTestEvent(TABLE_MAP_EVENT, len(log), 0, tableMapBody)...)
+
+	offset := int64(len(log))
+	rotateBody := append([]byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, []byte("mysql-bin.000002")...)
+	log = append(log, serializeTestEvent(ROTATE_EVENT, len(log), 0, rotateBody)...)
+
+	return log, offset
+}
+
+func TestOpenBinlogAt(t *testing.T) {
+	dir := t.TempDir()
+	log, offset := testPositionedBinlog()
+	writeTestFile(t, filepath.Join(dir, "mysql-bin.000001"), log)
+
+	b, err := OpenBinlogAt(dir, Position{File: "mysql-bin.000001", Offset: offset})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "t", b.TableMapCollection[42].TableName)
+	assert.Equal(t, BINLOG_CHECKSUM_ALG_CRC32, b.FormatDescription().ChecksumAlgorithm)
+
+	event, err := b.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ROTATE_EVENT, event.Type())
+	assert.Equal(t, offset, event.Position())
+}
+
+func TestOpenBinlogAtInvalidPosition(t *testing.T) {
+	dir := t.TempDir()
+	log, offset := testPositionedBinlog()
+	writeTestFile(t, filepath.Join(dir, "mysql-bin.000001"), log)
+
+	for _, invalid := range []int64{0, offset - 1, offset + 1, int64(len(log)) + 1} {
+		_, err := OpenBinlogAt(dir, Position{File: "mysql-bin.000001", Offset: invalid})
+		assert.ErrorIs(t, err, ErrInvalidPosition, "offset %v", invalid)
+	}
+}
+
+func TestStreamBinlogAt(t *testing.T) {
+	dir := t.TempDir()
+	log, offset := testPositionedBinlog()
+	writeTestFile(t, filepath.Join(dir, "mysql-bin.000001"), log)
+
+	stream, err := StreamBinlogAt(dir, Position{File: "mysql-bin.000001", Offset: offset})
+	assert.NoError(t, err)
+	defer stream.Close()
+
+	assert.Equal(t, "t", stream.TableMapCollection[42].TableName)
+	assert.Equal(t, Position{File: "mysql-bin.000001", Offset: offset}, stream.Position())
+
+	_, err = StreamBinlogAt(dir, Position{File: "mysql-bin.000001", Offset: offset + 1})
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+}
@@ -0,0 +1,336 @@
+package binlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	. "github.com/granicus/mysql-binlog-go/deserialization"
+)
+
+type QueryEvent struct {
+	ThreadId      uint32
+	ExecutionTime uint32
+	ErrorCode     uint16
+	StatusVars    *QueryStatusVars
+	DatabaseName  string
+	Query         string
+}
+
+/*
+QUERY EVENT DATA
+================
+
+Let:
+S = status vars length
+X = database name length
+Q = rest of the event
+
+Fixed:
+4 bytes = thread id
+4 bytes = execution time
+1 byte  = database name length
+2 bytes = error code
+2 bytes = status vars length
+
+Variable:
+S bytes   = status vars (see below)
+X+1 bytes = database name (null terminated)
+Q bytes   = query text (NOT null terminated)
+
+*/
+
+func (b *Binlog) DeserializeQueryEvent(header *EventHeader) (EventData, error) {
+	e := new(QueryEvent)
+	var err error
+
+	e.ThreadId, err = ReadUint32(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	e.ExecutionTime, err = ReadUint32(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	databaseNameLength, err := ReadUint8(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	e.ErrorCode, err = ReadUint16(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	statusVarsLength, err := ReadUint16(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = b.skipPostHeader(header.Type, 4+4+1+2+2); err != nil {
+		return nil, err
+	}
+
+	statusVarsBytes, err := ReadBytes(b.reader, int(statusVarsLength))
+	if err != nil {
+		return nil, err
+	}
+
+	e.StatusVars, err = DeserializeQueryStatusVars(statusVarsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	e.DatabaseName, err = ReadString(b.reader, int(databaseNameLength))
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip null terminator
+	if _, err = b.reader.Seek(1, 1); err != nil {
+		return nil, err
+	}
+
+	queryLength, err := b.remainingEventLength(header)
+	if err != nil {
+		return nil, err
+	}
+
+	e.Query, err = ReadString(b.reader, int(queryLength))
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+type QueryStatusVarCode byte
+
+const (
+	Q_FLAGS2_CODE QueryStatusVarCode = iota
+	Q_SQL_MODE_CODE
+	Q_CATALOG_CODE
+	Q_AUTO_INCREMENT
+	Q_CHARSET_CODE
+	Q_TIME_ZONE_CODE
+	Q_CATALOG_NZ_CODE
+	Q_LC_TIME_NAMES_CODE
+	Q_CHARSET_DATABASE_CODE
+	Q_TABLE_MAP_FOR_UPDATE_CODE
+	Q_MASTER_DATA_WRITTEN_CODE
+	Q_INVOKER
+	Q_UPDATED_DB_NAMES
+	Q_MICROSECONDS
+	Q_COMMIT_TS
+	Q_COMMIT_TS2
+	Q_EXPLICIT_DEFAULTS_FOR_TIMESTAMP
+	Q_DDL_LOGGED_WITH_XID
+	Q_DEFAULT_COLLATION_FOR_UTF8MB4
+	Q_SQL_REQUIRE_PRIMARY_KEY
+	Q_DEFAULT_TABLE_ENCRYPTION
+)
+
+// Written as the count in Q_UPDATED_DB_NAMES when too many
+// databases were updated to list them
+const OVER_MAX_DBS_IN_EVENT_MTS uint8 = 254
+
+type QueryStatusVars struct {
+	Flags2                       uint32
+	SqlMode                      uint64
+	Catalog                      string
+	AutoIncrementIncrement       uint16
+	AutoIncrementOffset          uint16
+	CharsetClient                uint16
+	CollationConnection          uint16
+	CollationServer              uint16
+	TimeZone                     string
+	LcTimeNames                  uint16
+	CharsetDatabase              uint16
+	TableMapForUpdate            uint64
+	MasterDataWritten            uint32
+	InvokerUser                  string
+	InvokerHost                  string
+	UpdatedDbNames               []string
+	Microseconds                 uint32
+	ExplicitDefaultsForTimestamp bool
+	DdlXid                       uint64
+	DefaultCollationForUtf8mb4   uint16
+	SqlRequirePrimaryKey         uint8
+	DefaultTableEncryption       uint8
+
+	// Anything after a status var code we don't know about
+	// (the length of an unknown var can't be determined)
+	Unparsed []byte
+
+	present uint64
+}
+
+// Whether the status var was written into the event
+func (v *QueryStatusVars) Has(code QueryStatusVarCode) bool {
+	return v.present&(1<<uint(code)) != 0
+}
+
+/*
+QUERY STATUS VARS
+=================
+
+A sequence of (1 byte code, value) pairs. The length
+of each value is determined by its code:
+
+Q_FLAGS2_CODE                     4 bytes
+Q_SQL_MODE_CODE                   8 bytes
+Q_CATALOG_CODE                    1 byte length + string + null
+Q_AUTO_INCREMENT                  2 bytes increment + 2 bytes offset
+Q_CHARSET_CODE                    2 bytes client + 2 connection + 2 server
+Q_TIME_ZONE_CODE                  1 byte length + string
+Q_CATALOG_NZ_CODE                 1 byte length + string
+Q_LC_TIME_NAMES_CODE              2 bytes
+Q_CHARSET_DATABASE_CODE           2 bytes
+Q_TABLE_MAP_FOR_UPDATE_CODE       8 bytes
+Q_MASTER_DATA_WRITTEN_CODE        4 bytes
+Q_INVOKER                         1 byte length + user + 1 byte length + host
+Q_UPDATED_DB_NAMES                1 byte count + count null terminated strings
+Q_MICROSECONDS                    3 bytes
+Q_COMMIT_TS                       unused
+Q_COMMIT_TS2                      unused
+Q_EXPLICIT_DEFAULTS_FOR_TIMESTAMP 1 byte
+Q_DDL_LOGGED_WITH_XID             8 bytes
+Q_DEFAULT_COLLATION_FOR_UTF8MB4   2 bytes
+Q_SQL_REQUIRE_PRIMARY_KEY         1 byte
+Q_DEFAULT_TABLE_ENCRYPTION        1 byte
+
+*/
+
+func readLengthPrefixedString(r io.Reader) (string, error) {
+	length, err := ReadUint8(r)
+	if err != nil {
+		return "", err
+	}
+
+	return ReadString(r, int(length))
+}
+
+func readUint24(r io.Reader) (uint32, error) {
+	b, err := ReadBytes(r, 3)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16, nil
+}
+
+func DeserializeQueryStatusVars(data []byte) (*QueryStatusVars, error) {
+	v := new(QueryStatusVars)
+	r := bytes.NewReader(data)
+
+	for r.Len() > 0 {
+		codeByte, err := ReadByte(r)
+		if err != nil {
+			return nil, err
+		}
+
+		code := QueryStatusVarCode(codeByte)
+
+		switch code {
+		case Q_FLAGS2_CODE:
+			v.Flags2, err = ReadUint32(r)
+
+		case Q_SQL_MODE_CODE:
+			v.SqlMode, err = ReadUint64(r)
+
+		case Q_CATALOG_CODE:
+			v.Catalog, err = readLengthPrefixedString(r)
+			if err == nil {
+				_, err = ReadByte(r)
+			}
+
+		case Q_AUTO_INCREMENT:
+			v.AutoIncrementIncrement, err = ReadUint16(r)
+			if err == nil {
+				v.AutoIncrementOffset, err = ReadUint16(r)
+			}
+
+		case Q_CHARSET_CODE:
+			v.CharsetClient, err = ReadUint16(r)
+			if err == nil {
+				v.CollationConnection, err = ReadUint16(r)
+			}
+			if err == nil {
+				v.CollationServer, err = ReadUint16(r)
+			}
+
+		case Q_TIME_ZONE_CODE:
+			v.TimeZone, err = readLengthPrefixedString(r)
+
+		case Q_CATALOG_NZ_CODE:
+			v.Catalog, err = readLengthPrefixedString(r)
+
+		case Q_LC_TIME_NAMES_CODE:
+			v.LcTimeNames, err = ReadUint16(r)
+
+		case Q_CHARSET_DATABASE_CODE:
+			v.CharsetDatabase, err = ReadUint16(r)
+
+		case Q_TABLE_MAP_FOR_UPDATE_CODE:
+			v.TableMapForUpdate, err = ReadUint64(r)
+
+		case Q_MASTER_DATA_WRITTEN_CODE:
+			v.MasterDataWritten, err = ReadUint32(r)
+
+		case Q_INVOKER:
+			v.InvokerUser, err = readLengthPrefixedString(r)
+			if err == nil {
+				v.InvokerHost, err = readLengthPrefixedString(r)
+			}
+
+		case Q_UPDATED_DB_NAMES:
+			var count uint8
+			count, err = ReadUint8(r)
+
+			if err == nil && count != OVER_MAX_DBS_IN_EVENT_MTS {
+				v.UpdatedDbNames = make([]string, count)
+				for i := range v.UpdatedDbNames {
+					v.UpdatedDbNames[i], err = ReadNullTerminatedString(r)
+					if err != nil {
+						break
+					}
+				}
+			}
+
+		case Q_MICROSECONDS:
+			v.Microseconds, err = readUint24(r)
+
+		case Q_EXPLICIT_DEFAULTS_FOR_TIMESTAMP:
+			var b byte
+			b, err = ReadByte(r)
+			v.ExplicitDefaultsForTimestamp = b != 0
+
+		case Q_DDL_LOGGED_WITH_XID:
+			v.DdlXid, err = ReadUint64(r)
+
+		case Q_DEFAULT_COLLATION_FOR_UTF8MB4:
+			v.DefaultCollationForUtf8mb4, err = ReadUint16(r)
+
+		case Q_SQL_REQUIRE_PRIMARY_KEY:
+			v.SqlRequirePrimaryKey, err = ReadUint8(r)
+
+		case Q_DEFAULT_TABLE_ENCRYPTION:
+			v.DefaultTableEncryption, err = ReadUint8(r)
+
+		default:
+			// No way to know how long this is, keep the rest raw
+			v.Unparsed = data[len(data)-r.Len()-1:]
+			return v, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: status var %v: %v", ErrMalformedEvent, code, err)
+		}
+
+		v.present |= 1 << uint(code)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,54 @@
+package binlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeserializeQueryEvent(t *testing.T) {
+	statusVars := []byte{
+		byte(Q_FLAGS2_CODE), 0x00, 0x00, 0x00, 0x00,
+		byte(Q_SQL_MODE_CODE), 0x00, 0x00, 0x20, 0x40, 0x00, 0x00, 0x00, 0x00,
+		byte(Q_CATALOG_NZ_CODE), 0x03, 's', 't', 'd',
+		byte(Q_CHARSET_CODE), 0x21, 0x00, 0x21, 0x00, 0x08, 0x00,
+		byte(Q_INVOKER), 0x04, 'r', 'o', 'o', 't', 0x09, 'l', 'o', 'c', 'a', 'l', 'h', 'o', 's', 't',
+		byte(Q_UPDATED_DB_NAMES), 0x02, 'a', 0x00, 'b', 'c', 0x00,
+		byte(Q_MICROSECONDS), 0x40, 0x42, 0x0f,
+	}
+
+	body := []byte{
+		0x2a, 0x00, 0x00, 0x00, // thread id
+		0x01, 0x00, 0x00, 0x00, // execution time
+		0x04,       // database name length
+		0x00, 0x00, // error code
+		byte(len(statusVars)), 0x00,
+	}
+	body = append(body, statusVars...)
+	body = append(body, 't', 'e', 's', 't', 0x00)
+	body = append(body, []byte("BEGIN")...)
+
+	b, header := newTestBinlog(QUERY_EVENT, body)
+
+	data, err := b.DeserializeQueryEvent(header)
+	assert.NoError(t, err)
+
+	e := data.(*QueryEvent)
+	assert.Equal(t, uint32(42), e.ThreadId)
+	assert.Equal(t, uint32(1), e.ExecutionTime)
+	assert.Equal(t, "test", e.DatabaseName)
+	assert.Equal(t, "BEGIN", e.Query)
+
+	v := e.StatusVars
+	assert.True(t, v.Has(Q_SQL_MODE_CODE))
+	assert.False(t, v.Has(Q_TIME_ZONE_CODE))
+	assert.Equal(t, uint64(0x40200000), v.SqlMode)
+	assert.Equal(t, "std", v.Catalog)
+	assert.Equal(t, uint16(33), v.CharsetClient)
+	assert.Equal(t, uint16(8), v.CollationServer)
+	assert.Equal(t, "root", v.InvokerUser)
+	assert.Equal(t, "localhost", v.InvokerHost)
+	assert.Equal(t, []string{"a", "bc"}, v.UpdatedDbNames)
+	assert.Equal(t, uint32(1000000), v.Microseconds)
+	assert.Nil(t, v.Unparsed)
+}
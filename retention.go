@@ -0,0 +1,96 @@
+package binlog
+
+/*
+EVENT RETENTION
+===============
+
+A StreamingBinlog or NetworkBinlog holds the events it reads (and
+their bytes, in an AppendableBuffer) so they can be decoded lazily.
+Left alone that grows forever, so once events have been returned by
+Next they are released after the stream has moved retention bytes
+past them:
+
+- released events drop out of Events(), and Event.Data() fails with
+  ErrEventReleased unless it was already called
+- their bytes are discarded from the buffer, which reuses the memory
+
+Release happens in batches of at least retention bytes, so a stream
+holds at most about twice the retention plus the events it hasn't
+returned yet. TABLE_MAP_EVENTs and FORMAT_DESCRIPTION_EVENTs are
+decoded as they are read, so TableMapCollection stays current no
+matter what is released.
+
+While a TransactionIterator is collecting a transaction, nothing
+from the transaction's start on is released, however large the
+transaction gets.
+
+Rotating to the next file releases everything from the previous one.
+
+*/
+
+// Default for StreamingBinlog and NetworkBinlog, see SetRetention
+const DEFAULT_RETENTION int64 = 4 << 20
+
+// Implemented by sources that release events, so TransactionIterator
+// can keep the transaction it is collecting
+type eventHolder interface {
+	holdEvents(from int64)
+	unholdEvents()
+}
+
+// Nothing from position from on is released until unholdEvents
+func (b *Binlog) holdEvents(from int64) {
+	b.holding = true
+	b.heldFrom = from
+}
+
+func (b *Binlog) unholdEvents() {
+	b.holding = false
+}
+
+// Releases the events returned by Next that end more than retention
+// bytes before the end of the last one returned. A negative retention
+// keeps everything.
+func (b *Binlog) releaseEvents(buffer *AppendableBuffer, retention int64) {
+	if retention < 0 || b.nextEventIndex == 0 {
+		return
+	}
+
+	last := b.events[b.nextEventIndex-1]
+	cutoff := last.Position() + int64(last.header.Length) - retention
+	if b.holding && cutoff > b.heldFrom {
+		cutoff = b.heldFrom
+	}
+
+	if released := cutoff - buffer.Start(); released <= 0 || released < retention {
+		return
+	}
+
+	n := 0
+	for n < b.nextEventIndex && b.events[n].Position()+int64(b.events[n].header.Length) <= cutoff {
+		n++
+	}
+
+	if n == 0 {
+		return
+	}
+
+	released := b.events[n-1]
+	buffer.Discard(released.Position() + int64(released.header.Length))
+
+	b.dropEvents(n)
+}
+
+// Drops the first n events from events. Events from a file can
+// still be decoded afterwards, the file is still there.
+func (b *Binlog) dropEvents(n int) {
+	// Copied down rather than resliced, so the array doesn't keep
+	// the dropped events around
+	remaining := copy(b.events, b.events[n:])
+	for i := remaining; i < len(b.events); i++ {
+		b.events[i] = nil
+	}
+
+	b.events = b.events[:remaining]
+	b.nextEventIndex -= n
+}
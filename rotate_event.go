@@ -0,0 +1,47 @@
+package binlog
+
+import (
+	. "github.com/granicus/mysql-binlog-go/deserialization"
+)
+
+type RotateEvent struct {
+	Position uint64
+	NextFile string
+}
+
+/*
+ROTATE EVENT DATA
+=================
+
+Let:
+F = rest of the event
+
+Fixed:
+8 bytes = position of the first event in the next file
+
+Variable:
+F bytes = next file name (NOT null terminated)
+
+*/
+
+func (b *Binlog) DeserializeRotateEvent(header *EventHeader) (EventData, error) {
+	e := new(RotateEvent)
+	var err error
+
+	e.Position, err = ReadUint64(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	nextFileLength, err := b.remainingEventLength(header)
+	if err != nil {
+		return nil, err
+	}
+
+	e.NextFile, err = ReadString(b.reader, int(nextFileLength))
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
@@ -0,0 +1,21 @@
+package binlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeserializeRotateEvent(t *testing.T) {
+	body := []byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	body = append(body, []byte("mysql-bin.000002")...)
+
+	b, header := newTestBinlog(ROTATE_EVENT, body)
+
+	data, err := b.DeserializeRotateEvent(header)
+	assert.NoError(t, err)
+
+	e := data.(*RotateEvent)
+	assert.Equal(t, uint64(4), e.Position)
+	assert.Equal(t, "mysql-bin.000002", e.NextFile)
+}
@@ -5,251 +5,514 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"log"
+	"math/big"
 	"time"
 
+	"github.com/granicus/mysql-binlog-go/bitset"
+	"github.com/granicus/mysql-binlog-go/charset"
 	"github.com/granicus/mysql-binlog-go/date"
 	"github.com/granicus/mysql-binlog-go/deserialization"
+	"github.com/granicus/mysql-binlog-go/geometry"
+	"github.com/granicus/mysql-binlog-go/jsonb"
 )
 
 type RowImage []RowImageCell
 
-// TODO: decide how to structure this relationally to table
-type RowImageCell interface{}
+// Every cell implements Value (see value.go)
+type RowImageCell = Value
 
 type NullRowImageCell MysqlType
 type NumberRowImageCell int64
+type UnsignedNumberRowImageCell uint64
 type FloatingPointNumberRowImageCell float32
 type LargeFloatingPointNumberRowImageCell float64
 
-// type BlobRowImageCell []byte
-type StringRowImageCell struct {
-	Type  MysqlType
-	Value string
+// CHAR, VARCHAR, BINARY, VARBINARY, TEXT and BLOB values. The
+// charset comes from the table map's optional metadata or the
+// schema, and is "" when neither knows it.
+type BytesRowImageCell struct {
+	Type    MysqlType
+	Charset string
+	Data    []byte
 }
-type TimestampRowImageCell time.Time
+
+// Whether the value is a BINARY, VARBINARY or BLOB rather than text
+func (c BytesRowImageCell) IsBinary() bool {
+	return charset.IsBinary(c.Charset)
+}
+
+// The value decoded to UTF-8. Values with an unknown charset are
+// assumed to be UTF-8 already.
+func (c BytesRowImageCell) Text() (string, error) {
+	if c.Charset == "" {
+		return string(c.Data), nil
+	}
+
+	return charset.Decode(c.Charset, c.Data)
+}
+
+// Text, or the raw bytes if they can't be decoded
+func (c BytesRowImageCell) String() string {
+	s, err := c.Text()
+	if err != nil {
+		return string(c.Data)
+	}
+
+	return s
+}
+
+// Type is MYSQL_TYPE_TIMESTAMP or MYSQL_TYPE_TIMESTAMP_V2, the zero
+// TIMESTAMP (0000-00-00 00:00:00) has a zero Timestamp
+type TimestampRowImageCell struct {
+	Type      MysqlType
+	Timestamp time.Time
+}
+
 type DateRowImageCell date.MysqlDate
 type TimeRowImageCell date.MysqlTime
 type DatetimeRowImageCell date.MysqlDatetime
 
+// MySQL stores the zero TIMESTAMP as 0 seconds, which isn't the epoch
+// (TIMESTAMP starts at 1970-01-01 00:00:01 UTC)
+func newTimestampRowImageCell(mysqlType MysqlType, timestamp time.Time) TimestampRowImageCell {
+	if timestamp.Unix() == 0 {
+		timestamp = time.Time{}
+	}
+
+	return TimestampRowImageCell{Type: mysqlType, Timestamp: timestamp}
+}
+
 func NewNullRowImageCell(mysqlType MysqlType) NullRowImageCell {
 	return NullRowImageCell(mysqlType)
 }
 
-func DeserializeRowImageCell(r io.Reader, tableMap *TableMapEvent, columnIndex int) RowImageCell {
-	mysqlType := tableMap.ColumnTypes[columnIndex]
+// Kept as a string so no precision is lost (DECIMAL can hold 65 digits)
+type DecimalRowImageCell struct {
+	Precision uint8
+	Scale     uint8
+	Digits    string
+}
 
-	switch mysqlType {
-	// impossible cases
-	case MYSQL_TYPE_ENUM, MYSQL_TYPE_NEWDATE, MYSQL_TYPE_SET,
-		MYSQL_TYPE_TINY_BLOB, MYSQL_TYPE_MEDIUM_BLOB, MYSQL_TYPE_LONG_BLOB:
-		log.Fatal("Impossible type found in binlog!")
+func (c DecimalRowImageCell) String() string {
+	return c.Digits
+}
 
+func (c DecimalRowImageCell) Rat() *big.Rat {
+	r, _ := new(big.Rat).SetString(c.Digits)
+	return r
+}
+
+func integerLength(mysqlType MysqlType) int {
+	switch mysqlType {
 	case MYSQL_TYPE_TINY:
-		v, err := deserialization.ReadInt8(r)
-		fatalErr(err)
+		return 1
+	case MYSQL_TYPE_SHORT:
+		return 2
+	case MYSQL_TYPE_INT24:
+		return 3
+	case MYSQL_TYPE_LONG:
+		return 4
+	}
 
-		return NumberRowImageCell(v)
+	return 8
+}
 
-	case MYSQL_TYPE_SHORT:
-		v, err := deserialization.ReadInt16(r)
-		fatalErr(err)
+// Integers are little endian. Signed values are sign extended from
+// their own width, so a signed MEDIUMINT of 0xffffff is -1.
+func readIntegerRowImageCell(r io.Reader, mysqlType MysqlType, unsigned bool) (RowImageCell, error) {
+	length := integerLength(mysqlType)
 
-		return NumberRowImageCell(v)
+	value, err := readLittleEndianUint(r, length)
+	if err != nil {
+		return nil, err
+	}
 
-	case MYSQL_TYPE_INT24:
-		b, err := deserialization.ReadBytes(r, 3)
-		fatalErr(err)
+	if unsigned {
+		return UnsignedNumberRowImageCell(value), nil
+	}
 
-		// pad and create buffer (for io.Reader)
-		buf := bytes.NewBuffer(append(b, byte(0)))
+	shift := uint(64 - 8*length)
+	return NumberRowImageCell(int64(value<<shift) >> shift), nil
+}
 
-		var value int32 = 0
-		fatalErr(binary.Read(buf, binary.LittleEndian, &value))
+// Index is 1 based (0 is the empty string MySQL stores for invalid
+// values). Member is only set when the members are known.
+type EnumRowImageCell struct {
+	Index  uint16
+	Member string
+}
 
-		return NumberRowImageCell(value)
+func newEnumRowImageCell(index uint16, members []string) EnumRowImageCell {
+	cell := EnumRowImageCell{Index: index}
 
-	case MYSQL_TYPE_LONG:
-		v, err := deserialization.ReadInt32(r)
-		fatalErr(err)
+	if index > 0 && int(index) <= len(members) {
+		cell.Member = members[index-1]
+	}
+
+	return cell
+}
+
+// Bit i of Bitmask is set when member i is in the set. Values is
+// only set when the members are known.
+type SetRowImageCell struct {
+	Bitmask uint64
+	Values  []string
+}
+
+func newSetRowImageCell(bitmask uint64, members []string) SetRowImageCell {
+	cell := SetRowImageCell{Bitmask: bitmask}
+
+	if members != nil {
+		cell.Values = []string{}
+
+		for i, member := range members {
+			if i < 64 && bitmask&(1<<uint(i)) != 0 {
+				cell.Values = append(cell.Values, member)
+			}
+		}
+	}
+
+	return cell
+}
+
+// ENUM and SET values are stored in as few bytes as their members need
+func readLittleEndianUint(r io.Reader, length int) (uint64, error) {
+	b, err := deserialization.ReadBytes(r, length)
+	if err != nil {
+		return 0, err
+	}
+
+	var value uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		value = value<<8 | uint64(b[i])
+	}
+
+	return value, nil
+}
+
+// BIT(n) value, bit 0 is the least significant bit
+type BitRowImageCell struct {
+	Length uint8
+	Bits   uint64
+}
+
+func (c BitRowImageCell) Bitset() bitset.Bitset {
+	return bitset.MakeFromUint64(c.Bits, uint(c.Length))
+}
+
+// Data is the tree returned by jsonb.Decode
+type JSONRowImageCell struct {
+	Data interface{}
+}
 
-		return NumberRowImageCell(v)
+// The value as JSON text, formatted the way MySQL prints it
+func (c JSONRowImageCell) String() string {
+	return jsonb.Format(c.Data)
+}
 
-	case MYSQL_TYPE_LONGLONG:
-		v, err := deserialization.ReadInt64(r)
-		fatalErr(err)
+type GeometryRowImageCell struct {
+	SRID     uint32
+	WKB      []byte
+	Geometry geometry.Geometry
+}
 
-		return NumberRowImageCell(v)
+func (c GeometryRowImageCell) WKT() string {
+	return c.Geometry.WKT()
+}
+
+func (c GeometryRowImageCell) String() string {
+	return c.WKT()
+}
+
+// BLOB-like values (BLOB, JSON, GEOMETRY) are prefixed with their
+// length, which takes up pack size bytes
+func readBlobBytes(r io.Reader, metadata *ColumnMetadata) ([]byte, error) {
+	lengthBytes, err := deserialization.ReadBytes(r, int(metadata.PackSize()))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lengthBytes) != 8 {
+		padding := make([]byte, 8-len(lengthBytes))
+		for i := range padding {
+			padding[i] = byte(0)
+		}
+
+		lengthBytes = append(lengthBytes, padding...)
+	}
+
+	length := binary.LittleEndian.Uint64(lengthBytes)
+
+	return deserialization.ReadBytes(r, int(length))
+}
+
+func DeserializeRowImageCell(r io.Reader, tableMap *TableMapEvent, columnIndex int) (RowImageCell, error) {
+	mysqlType := tableMap.ColumnTypes[columnIndex]
+
+	switch mysqlType {
+	// impossible cases
+	case MYSQL_TYPE_ENUM, MYSQL_TYPE_NEWDATE, MYSQL_TYPE_SET,
+		MYSQL_TYPE_TINY_BLOB, MYSQL_TYPE_MEDIUM_BLOB, MYSQL_TYPE_LONG_BLOB:
+		return nil, fmt.Errorf("%w: impossible type %v found in binlog", ErrMalformedEvent, mysqlType)
+
+	case MYSQL_TYPE_TINY, MYSQL_TYPE_SHORT, MYSQL_TYPE_INT24, MYSQL_TYPE_LONG, MYSQL_TYPE_LONGLONG:
+		return readIntegerRowImageCell(r, mysqlType, tableMap.IsUnsigned(columnIndex))
 
 	case MYSQL_TYPE_FLOAT:
 		var v float32
 		b, err := deserialization.ReadBytes(r, 4)
-		fatalErr(err)
+		if err != nil {
+			return nil, err
+		}
 
-		fatalErr(binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &v))
+		if err = binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &v); err != nil {
+			return nil, err
+		}
 
-		return FloatingPointNumberRowImageCell(v)
+		return FloatingPointNumberRowImageCell(v), nil
 
 	case MYSQL_TYPE_DOUBLE:
 		// Not sure if C doubles convert to Go float64 properly
 		var v float64
 		b, err := deserialization.ReadBytes(r, 8)
-		fatalErr(err)
+		if err != nil {
+			return nil, err
+		}
 
-		fatalErr(binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &v))
+		if err = binary.Read(bytes.NewBuffer(b), binary.LittleEndian, &v); err != nil {
+			return nil, err
+		}
 
-		return LargeFloatingPointNumberRowImageCell(v)
+		return LargeFloatingPointNumberRowImageCell(v), nil
 
 	case MYSQL_TYPE_NULL:
-		return NewNullRowImageCell(mysqlType)
-
-	case MYSQL_TYPE_TIMESTAMP, MYSQL_TYPE_TIME, MYSQL_TYPE_DATETIME:
-		log.Fatal("time fields disabled")
+		return NewNullRowImageCell(mysqlType), nil
 
 	case MYSQL_TYPE_DATE:
 		date, err := deserialization.ReadDate(r)
-		fatalErr(err)
+		if err != nil {
+			return nil, err
+		}
+
+		return DateRowImageCell(date), nil
 
-		return DateRowImageCell(date)
+	case MYSQL_TYPE_TIME:
+		time, err := deserialization.ReadTime(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return TimeRowImageCell(time), nil
 
 	case MYSQL_TYPE_TIME_V2:
-		time, err := deserialization.ReadTimeV2(r)
-		fatalErr(err)
+		time, err := deserialization.ReadTimeV2(r, tableMap.Metadata[columnIndex])
+		if err != nil {
+			return nil, err
+		}
 
-		return TimeRowImageCell(time)
+		return TimeRowImageCell(time), nil
+
+	case MYSQL_TYPE_DATETIME:
+		datetime, err := deserialization.ReadDatetime(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return DatetimeRowImageCell(datetime), nil
 
 	case MYSQL_TYPE_DATETIME_V2:
 		datetime, err := deserialization.ReadDatetimeV2(r, tableMap.Metadata[columnIndex])
-		fatalErr(err)
+		if err != nil {
+			return nil, err
+		}
 
-		return DatetimeRowImageCell(datetime)
+		return DatetimeRowImageCell(datetime), nil
+
+	case MYSQL_TYPE_TIMESTAMP:
+		timestamp, err := deserialization.ReadTimestamp(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return newTimestampRowImageCell(MYSQL_TYPE_TIMESTAMP, timestamp), nil
 
 	case MYSQL_TYPE_TIMESTAMP_V2:
 		timestamp, err := deserialization.ReadTimestampV2(r, tableMap.Metadata[columnIndex])
-		fatalErr(err)
+		if err != nil {
+			return nil, err
+		}
 
-		return TimestampRowImageCell(timestamp)
+		return newTimestampRowImageCell(MYSQL_TYPE_TIMESTAMP_V2, timestamp), nil
 
 	case MYSQL_TYPE_YEAR:
 		v, err := deserialization.ReadUint8(r)
-		fatalErr(err)
+		if err != nil {
+			return nil, err
+		}
 
-		return NumberRowImageCell(1900 + uint64(v))
+		return NumberRowImageCell(1900 + uint64(v)), nil
 
 	case MYSQL_TYPE_BIT:
-		log.Fatal("BIT currently disabled")
-		// metadata := tableMap.Metadata[columnIndex]
+		metadata := tableMap.Metadata[columnIndex]
+
+		// Big endian, only as many bytes as the bits need
+		b, err := deserialization.ReadBytes(r, int(metadata.PackSize()))
+		if err != nil {
+			return nil, err
+		}
+
+		var value uint64
+		for _, c := range b {
+			value = value<<8 | uint64(c)
+		}
+
+		return BitRowImageCell{
+			Length: metadata.BitsetLength(),
+			Bits:   value,
+		}, nil
 
 	case MYSQL_TYPE_NEWDECIMAL:
-		// Not currently supported, may never be supported
-		log.Fatal("NEWDECIMAL values are not supported.")
+		metadata := tableMap.Metadata[columnIndex]
 
-	case MYSQL_TYPE_VARCHAR:
-		// If you see this and this has been working fine for a while, remove this
-		tempErr := func(err error) {
-			if err != nil {
-				fmt.Println("!!! SOMETHING WENT WRONG IN VARCHAR")
-				fmt.Println("Hint: lengthBytes var may be stored as packed int or based on max length (use metadata).")
-				fatalErr(err)
-			}
+		v, err := deserialization.ReadDecimal(r, metadata)
+		if err != nil {
+			return nil, err
 		}
 
+		return DecimalRowImageCell{
+			Precision: metadata.Precision(),
+			Scale:     metadata.Decimals(),
+			Digits:    v,
+		}, nil
+
+	case MYSQL_TYPE_VARCHAR:
 		metadata := tableMap.Metadata[columnIndex]
 
 		var length uint16
 		var err error
 
+		// NOTE: length may be stored as packed int or based on max length
 		if metadata.MaxLength() <= 255 {
 			smallLength, err := deserialization.ReadUint8(r)
-			tempErr(err)
+			if err != nil {
+				return nil, err
+			}
 
 			length = uint16(smallLength)
 		} else {
 			length, err = deserialization.ReadUint16(r)
-			tempErr(err)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		b, err := deserialization.ReadBytes(r, int(length))
-		tempErr(err)
-
-		return StringRowImageCell{
-			Type:  mysqlType,
-			Value: string(b),
+		if err != nil {
+			return nil, err
 		}
 
-	case MYSQL_TYPE_STRING, MYSQL_TYPE_VAR_STRING:
-		tempErr := func(err error) {
-			if err != nil {
-				fmt.Println("!!! SOMETHING WENT WRONG IN STRING/VAR_STRING")
-				fatalErr(err)
-			}
-		}
+		return BytesRowImageCell{
+			Type:    mysqlType,
+			Charset: tableMap.ColumnCharset(columnIndex),
+			Data:    b,
+		}, nil
 
+	case MYSQL_TYPE_STRING, MYSQL_TYPE_VAR_STRING:
 		metadata := tableMap.Metadata[columnIndex]
 
-		if metadata.RealType() == MYSQL_TYPE_ENUM {
-			b, err := deserialization.ReadBytes(r, int(metadata.PackSize()))
-			tempErr(err)
+		switch metadata.RealType() {
+		case MYSQL_TYPE_ENUM:
+			index, err := readLittleEndianUint(r, int(metadata.PackSize()))
+			if err != nil {
+				return nil, err
+			}
 
-			enumString := string(b[0] + byte(48))
+			return newEnumRowImageCell(uint16(index), tableMap.EnumValues(columnIndex)), nil
 
-			return StringRowImageCell{
-				Type:  metadata.RealType(),
-				Value: enumString,
+		case MYSQL_TYPE_SET:
+			bitmask, err := readLittleEndianUint(r, int(metadata.PackSize()))
+			if err != nil {
+				return nil, err
 			}
+
+			return newSetRowImageCell(bitmask, tableMap.SetValues(columnIndex)), nil
 		}
 
 		var length uint16
 		var err error
 
-		if metadata.PackSize() <= 255 {
+		if metadata.MaxLength() <= 255 {
 			smallLength, err := deserialization.ReadUint8(r)
-			tempErr(err)
+			if err != nil {
+				return nil, err
+			}
 
 			length = uint16(smallLength)
 		} else {
 			length, err = deserialization.ReadUint16(r)
-			tempErr(err)
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		b, err := deserialization.ReadBytes(r, int(length))
-		tempErr(err)
-
-		return StringRowImageCell{
-			Type:  metadata.RealType(),
-			Value: string(b),
+		if err != nil {
+			return nil, err
 		}
 
+		return BytesRowImageCell{
+			Type:    metadata.RealType(),
+			Charset: tableMap.ColumnCharset(columnIndex),
+			Data:    b,
+		}, nil
+
 	case MYSQL_TYPE_BLOB:
-		metadata := tableMap.Metadata[columnIndex]
-		lengthBytes, err := deserialization.ReadBytes(r, int(metadata.PackSize()))
-		fatalErr(err)
+		b, err := readBlobBytes(r, tableMap.Metadata[columnIndex])
+		if err != nil {
+			return nil, err
+		}
 
-		if len(lengthBytes) != 8 {
-			padding := make([]byte, 8-len(lengthBytes))
-			for i := range padding {
-				padding[i] = byte(0)
-			}
+		return BytesRowImageCell{
+			Type:    MYSQL_TYPE_BLOB,
+			Charset: tableMap.ColumnCharset(columnIndex),
+			Data:    b,
+		}, nil
 
-			lengthBytes = append(lengthBytes, padding...)
+	case MYSQL_TYPE_JSON:
+		b, err := readBlobBytes(r, tableMap.Metadata[columnIndex])
+		if err != nil {
+			return nil, err
 		}
 
-		length := binary.LittleEndian.Uint64(lengthBytes)
+		v, err := jsonb.Decode(b)
+		if err != nil {
+			return nil, err
+		}
 
-		b, err := deserialization.ReadBytes(r, int(length))
-		fatalErr(err)
+		return JSONRowImageCell{Data: v}, nil
 
-		return StringRowImageCell{
-			Type:  MYSQL_TYPE_BLOB,
-			Value: string(b),
+	case MYSQL_TYPE_GEOMETRY:
+		b, err := readBlobBytes(r, tableMap.Metadata[columnIndex])
+		if err != nil {
+			return nil, err
 		}
 
-	case MYSQL_TYPE_DECIMAL, MYSQL_TYPE_GEOMETRY:
-		log.Fatal("Mysql type discovered but not supported at this time.")
+		srid, g, err := geometry.DecodeMysql(b)
+		if err != nil {
+			return nil, err
+		}
 
-	default:
-		log.Fatal("Unsupported mysql type:", mysqlType)
+		return GeometryRowImageCell{
+			SRID:     srid,
+			WKB:      b[4:],
+			Geometry: g,
+		}, nil
 	}
 
-	return NewNullRowImageCell(mysqlType)
+	// Not supported at this time: DECIMAL and anything we don't know
+	// about
+	return nil, &UnsupportedColumnTypeError{
+		Type:        mysqlType,
+		ColumnIndex: columnIndex,
+	}
 }
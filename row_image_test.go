@@ -246,3 +246,206 @@ func TestDeserializeRowImageCell(t *testing.T) {
 	// a bunch of data assertions
 }
 */
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/granicus/mysql-binlog-go/date"
+	"github.com/stretchr/testify/assert"
+)
+
+// Table map with a single column of the given type and raw metadata
+func testTableMap(mysqlType MysqlType, metadata []byte) *TableMapEvent {
+	column, err := DeserializeColomnMetadata(bytes.NewReader(metadata), mysqlType)
+	if err != nil {
+		panic(err)
+	}
+
+	return &TableMapEvent{
+		NumberOfColumns: 1,
+		ColumnTypes:     []MysqlType{mysqlType},
+		Metadata:        []*ColumnMetadata{column},
+	}
+}
+
+func TestDeserializeBitRowImageCell(t *testing.T) {
+	cases := []struct {
+		metadata []byte
+		b        []byte
+		length   uint8
+		value    uint64
+	}{
+		{[]byte{0x01, 0x00}, []byte{0x01}, 1, 1},
+		{[]byte{0x00, 0x01}, []byte{0x55}, 8, 0x55},
+		{[]byte{0x02, 0x01}, []byte{0x02, 0x01}, 10, 0x201},
+		{[]byte{0x00, 0x08}, []byte{0x80, 0, 0, 0, 0, 0, 0, 0x01}, 64, 0x8000000000000001},
+	}
+
+	for _, c := range cases {
+		cell, err := DeserializeRowImageCell(bytes.NewReader(c.b), testTableMap(MYSQL_TYPE_BIT, c.metadata), 0)
+		assert.NoError(t, err)
+
+		bit := cell.(BitRowImageCell)
+		assert.Equal(t, c.length, bit.Length)
+		assert.Equal(t, c.value, bit.Bits)
+		assert.True(t, bit.Bitset().Bit(0))
+	}
+}
+
+func TestDeserializeEnumRowImageCell(t *testing.T) {
+	members := make([]string, 300)
+	for i := range members {
+		members[i] = string(rune('a' + i%26))
+	}
+	members[299] = "last"
+
+	tableMap := testTableMap(MYSQL_TYPE_STRING, []byte{byte(MYSQL_TYPE_ENUM), 0x02})
+
+	cell, err := DeserializeRowImageCell(bytes.NewReader([]byte{0x2c, 0x01}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, EnumRowImageCell{Index: 300}, cell)
+
+	tableMap.Schema = &TableSchema{Columns: []ColumnSchema{{EnumValues: members}}}
+
+	cell, err = DeserializeRowImageCell(bytes.NewReader([]byte{0x2c, 0x01}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, EnumRowImageCell{Index: 300, Member: "last"}, cell)
+}
+
+func TestDeserializeSetRowImageCell(t *testing.T) {
+	tableMap := testTableMap(MYSQL_TYPE_STRING, []byte{byte(MYSQL_TYPE_SET), 0x01})
+	tableMap.Schema = &TableSchema{Columns: []ColumnSchema{{SetValues: []string{"one", "two", "three"}}}}
+
+	cell, err := DeserializeRowImageCell(bytes.NewReader([]byte{0x05}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, SetRowImageCell{Bitmask: 5, Values: []string{"one", "three"}}, cell)
+}
+
+func TestDeserializeIntegerRowImageCell(t *testing.T) {
+	cases := []struct {
+		mysqlType MysqlType
+		b         []byte
+		signed    RowImageCell
+		unsigned  RowImageCell
+	}{
+		{MYSQL_TYPE_TINY, []byte{0xff}, NumberRowImageCell(-1), UnsignedNumberRowImageCell(255)},
+		{MYSQL_TYPE_SHORT, []byte{0x00, 0x80}, NumberRowImageCell(-32768), UnsignedNumberRowImageCell(32768)},
+		{MYSQL_TYPE_INT24, []byte{0xfe, 0xff, 0xff}, NumberRowImageCell(-2), UnsignedNumberRowImageCell(16777214)},
+		{MYSQL_TYPE_INT24, []byte{0x01, 0x02, 0x03}, NumberRowImageCell(0x030201), UnsignedNumberRowImageCell(0x030201)},
+		{MYSQL_TYPE_LONG, []byte{0xff, 0xff, 0xff, 0x7f}, NumberRowImageCell(2147483647), UnsignedNumberRowImageCell(2147483647)},
+		{
+			MYSQL_TYPE_LONGLONG, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80},
+			NumberRowImageCell(-9223372036854775808), UnsignedNumberRowImageCell(9223372036854775808),
+		},
+	}
+
+	for _, c := range cases {
+		tableMap := testTableMap(c.mysqlType, []byte{})
+
+		cell, err := DeserializeRowImageCell(bytes.NewReader(c.b), tableMap, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, c.signed, cell)
+
+		tableMap.Schema = &TableSchema{Columns: []ColumnSchema{{Unsigned: true}}}
+
+		cell, err = DeserializeRowImageCell(bytes.NewReader(c.b), tableMap, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, c.unsigned, cell)
+	}
+}
+
+func TestDeserializeLegacyTemporalRowImageCells(t *testing.T) {
+	cases := []struct {
+		mysqlType MysqlType
+		b         []byte
+		expected  RowImageCell
+	}{
+		{
+			MYSQL_TYPE_TIMESTAMP, []byte{0x00, 0x2f, 0x68, 0x59},
+			TimestampRowImageCell{Type: MYSQL_TYPE_TIMESTAMP, Timestamp: time.Unix(1500000000, 0)},
+		},
+		{
+			MYSQL_TYPE_TIMESTAMP, []byte{0x00, 0x00, 0x00, 0x00},
+			TimestampRowImageCell{Type: MYSQL_TYPE_TIMESTAMP},
+		},
+		{
+			MYSQL_TYPE_DATETIME, []byte{0xeb, 0x46, 0xf7, 0xeb, 0x5c, 0x12, 0x00, 0x00},
+			DatetimeRowImageCell(date.NewMysqlDatetime(2019, 3, 5, 14, 25, 7)),
+		},
+		{
+			MYSQL_TYPE_TIME, []byte{0xc0, 0x1d, 0xfe}, // -12:34:56
+			TimeRowImageCell(date.NewMysqlTimeWithFraction(true, 12, 34, 56, 0, 0)),
+		},
+	}
+
+	for _, c := range cases {
+		cell, err := DeserializeRowImageCell(bytes.NewReader(c.b), testTableMap(c.mysqlType, []byte{}), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, cell)
+	}
+}
+
+func TestDeserializeTimestampV2RowImageCell(t *testing.T) {
+	tableMap := testTableMap(MYSQL_TYPE_TIMESTAMP_V2, []byte{0x00})
+
+	cell, err := DeserializeRowImageCell(bytes.NewReader([]byte{0x59, 0x68, 0x2f, 0x00}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, TimestampRowImageCell{Type: MYSQL_TYPE_TIMESTAMP_V2, Timestamp: time.Unix(1500000000, 0)}, cell)
+
+	// 0000-00-00 00:00:00 rather than the epoch
+	cell, err = DeserializeRowImageCell(bytes.NewReader([]byte{0x00, 0x00, 0x00, 0x00}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.True(t, cell.(TimestampRowImageCell).IsZero())
+	assert.Equal(t, MYSQL_TYPE_TIMESTAMP_V2, cell.MysqlType())
+}
+
+func TestDeserializeGeometryRowImageCell(t *testing.T) {
+	value := []byte{0xe6, 0x10, 0x00, 0x00, 0x01, 0x01, 0x00, 0x00, 0x00}
+	value = append(value, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf0, 0x3f) // 1
+	value = append(value, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40) // 2
+
+	b := append([]byte{byte(len(value)), 0x00, 0x00, 0x00}, value...)
+
+	cell, err := DeserializeRowImageCell(bytes.NewReader(b), testTableMap(MYSQL_TYPE_GEOMETRY, []byte{0x04}), 0)
+	assert.NoError(t, err)
+
+	geometryCell := cell.(GeometryRowImageCell)
+	assert.Equal(t, uint32(4326), geometryCell.SRID)
+	assert.Equal(t, value[4:], geometryCell.WKB)
+	assert.Equal(t, "POINT(1 2)", geometryCell.WKT())
+}
+
+func TestDeserializeBytesRowImageCell(t *testing.T) {
+	tableMap := testTableMap(MYSQL_TYPE_VARCHAR, []byte{0x20, 0x00})
+
+	cell, err := DeserializeRowImageCell(bytes.NewReader([]byte{0x02, 0xff, 0x00}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, BytesRowImageCell{Type: MYSQL_TYPE_VARCHAR, Data: []byte{0xff, 0x00}}, cell)
+
+	tableMap.Schema = &TableSchema{Columns: []ColumnSchema{{Charset: "latin1"}}}
+
+	cell, err = DeserializeRowImageCell(bytes.NewReader([]byte{0x04, 'c', 'a', 'f', 0xe9}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.False(t, cell.(BytesRowImageCell).IsBinary())
+	assert.Equal(t, "café", cell.(BytesRowImageCell).String())
+
+	// Optional metadata wins over the schema
+	tableMap.OptionalMetadata = &TableMapOptionalMetadata{ColumnCharsets: []uint64{63}}
+
+	cell, err = DeserializeRowImageCell(bytes.NewReader([]byte{0x01, 0xe9}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.True(t, cell.(BytesRowImageCell).IsBinary())
+}
+
+func TestDeserializeLongCharRowImageCell(t *testing.T) {
+	// CHAR(256) in a single byte charset
+	tableMap := testTableMap(MYSQL_TYPE_STRING, []byte{0xee, 0x00})
+	assert.Equal(t, MYSQL_TYPE_STRING, tableMap.Metadata[0].RealType())
+	assert.Equal(t, uint16(256), tableMap.Metadata[0].MaxLength())
+
+	cell, err := DeserializeRowImageCell(bytes.NewReader([]byte{0x02, 0x00, 'a', 'b'}), tableMap, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, BytesRowImageCell{Type: MYSQL_TYPE_STRING, Data: []byte("ab")}, cell)
+}
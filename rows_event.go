@@ -1,31 +1,51 @@
 package binlog
 
 import (
-	"encoding/binary"
 	"fmt"
 
 	"github.com/granicus/mysql-binlog-go/bitset"
 	. "github.com/granicus/mysql-binlog-go/deserialization"
 )
 
+// For UPDATE events Rows alternates between before and after
+// images (see Updates), and AfterUsedSet holds the columns present
+// in the after images.
 type RowsEvent struct {
 	Type            MysqlBinlogEventType
 	TableId         uint64
 	NumberOfColumns uint64
 	UsedSet         bitset.Bitset
+	AfterUsedSet    bitset.Bitset
 	Rows            []RowImage
 }
 
-func (e *RowsEvent) UsedFields() int {
-	used := 0
+func countBits(set bitset.Bitset, length uint64) int {
+	count := 0
 
-	for i := uint(0); i < uint(e.NumberOfColumns); i++ {
-		if e.UsedSet.Bit(i) {
-			used++
+	for i := uint(0); i < uint(length); i++ {
+		if set.Bit(i) {
+			count++
 		}
 	}
 
-	return used
+	return count
+}
+
+func (e *RowsEvent) UsedFields() int {
+	return countBits(e.UsedSet, e.NumberOfColumns)
+}
+
+func (e *RowsEvent) IsUpdate() bool {
+	return isUpdateRowsEvent(e.Type)
+}
+
+func isUpdateRowsEvent(eventType MysqlBinlogEventType) bool {
+	switch eventType {
+	case UPDATE_ROWS_EVENTv0, UPDATE_ROWS_EVENTv1, UPDATE_ROWS_EVENTv2:
+		return true
+	}
+
+	return false
 }
 
 /*
@@ -40,109 +60,171 @@ K = number of false bits in null bitfield (not counting padding in last byte)
 U = 2 if update event, 1 for any other ones
 B = number of rows (determined by reading till data length reached)
 
-Fixed Section:
-6 bytes = table id
+Fixed Section (post-header):
+6 bytes = table id (4 bytes if the post-header length is 6)
 2 bytes = reserved (skip)
+2 bytes = extra info length (v2 only, includes these 2 bytes)
 
 Variable Section:
+E bytes = extra info (v2 only, E = extra info length - 2)
 1 byte  = packed int byte key (see ReadPackedInteger)
 P bytes = number of columns
 N bytes = column used bitfield
+N bytes = after image column used bitfield (update only)
 U * B * (
 	J bytes = null bitfield
 	K bytes = row image
 )
 
+J and K count the columns used by the image being read, and bit i
+of the null bitfield is the i-th used column (not column i).
+
 FOR ROW IMAGE CELL DESERIALIZATION:
 http://bazaar.launchpad.net/~mysql/mysql-server/5.6/view/head:/sql/log_event.cc#L1942
 
 */
 
-func (b *Binlog) DeserializeRowsEvent(header *EventHeader) EventData {
+func (b *Binlog) DeserializeRowsEvent(header *EventHeader) (EventData, error) {
 	e := new(RowsEvent)
 	e.Type = header.Type
 
-	// Read 6 bytes for table id, pad to 8, read as uint64
-	tableIdBytes, err := ReadBytes(b.reader, 6)
-	fatalErr(err)
-	e.TableId = binary.LittleEndian.Uint64(append(tableIdBytes, byte(0), byte(0)))
+	tableId, tableIdLength, err := b.readTableId(header.Type)
+	if err != nil {
+		return nil, err
+	}
+	e.TableId = tableId
 
 	// If the TableMapEvent has not been logged, find it and deserialize it
 	tableMap, ok := b.TableMapCollection[e.TableId]
 	if !ok {
 		// TODO: create position stash/pop system
 		oldPosition, err := b.reader.Seek(0, 1)
-		fatalErr(err)
+		if err != nil {
+			return nil, err
+		}
 
-		tableMap = b.findTableMapEvent(e.TableId)
+		tableMap, err = b.findTableMapEvent(e.TableId)
+		if err != nil {
+			return nil, err
+		}
 
-		_, err = b.reader.Seek(oldPosition, 0)
-		fatalErr(err)
+		if _, err = b.reader.Seek(oldPosition, 0); err != nil {
+			return nil, err
+		}
 	}
 
 	// Skip reserved bytes
-	_, err = b.reader.Seek(2, 1)
-	fatalErr(err)
+	if _, err = b.reader.Seek(2, 1); err != nil {
+		return nil, err
+	}
+
+	postHeaderConsumed := tableIdLength + 2
 
 	// Skip extra v2 row event info
+	var extraInfoLength uint16
 	switch header.Type {
 	case WRITE_ROWS_EVENTv2, UPDATE_ROWS_EVENTv2, DELETE_ROWS_EVENTv2:
-		extraInfoLength, err := ReadUint16(b.reader)
-		fatalErr(err)
+		extraInfoLength, err = ReadUint16(b.reader)
+		if err != nil {
+			return nil, err
+		}
+
+		postHeaderConsumed += 2
+	}
+
+	if err = b.skipPostHeader(header.Type, postHeaderConsumed); err != nil {
+		return nil, err
+	}
 
-		_, err = b.reader.Seek(int64(extraInfoLength-2), 1)
-		fatalErr(err)
+	if extraInfoLength > 2 {
+		if _, err = b.reader.Seek(int64(extraInfoLength-2), 1); err != nil {
+			return nil, err
+		}
 	}
 
 	e.NumberOfColumns, err = ReadPackedInteger(b.reader)
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(tableMap.ColumnTypes)) != e.NumberOfColumns {
+		return nil, fmt.Errorf("%w: table map does not contain expected number of column types %v %v",
+			ErrMalformedEvent, len(tableMap.ColumnTypes), e.NumberOfColumns)
+	}
 
 	e.UsedSet, err = ReadBitset(b.reader, int(e.NumberOfColumns))
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
+
+	if isUpdateRowsEvent(header.Type) {
+		e.AfterUsedSet, err = ReadBitset(b.reader, int(e.NumberOfColumns))
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	numberOfFields := e.UsedFields()
 	e.Rows = []RowImage{}
 
 	// Rows deserialization loop
 	for {
-		currentPos, err := b.reader.Seek(0, 1)
-		fatalErr(err)
-
 		// Check if there are any more rows to deserialize
-		if currentPos >= int64(header.NextPosition)-4 {
-			if currentPos > int64(header.NextPosition) {
-				panic(fmt.Errorf("** ROW EVENT OVERSHOT READING: %v", currentPos-int64(header.NextPosition)))
-			}
+		// (errors if we overshot the end of the event)
+		remaining, err := b.remainingEventLength(header)
+		if err != nil {
+			return nil, err
+		}
 
+		if remaining == 0 {
 			break
 		}
 
-		nullSet, err := ReadBitset(b.reader, numberOfFields)
-		fatalErr(err)
+		row, err := b.deserializeRowImage(tableMap, e.UsedSet)
+		if err != nil {
+			return nil, err
+		}
+		e.Rows = append(e.Rows, row)
 
-		if len(e.UsedSet) != len(nullSet) {
-			panic(fmt.Errorf("UsedSet and NullSet length mismatched"))
+		if isUpdateRowsEvent(header.Type) {
+			row, err = b.deserializeRowImage(tableMap, e.AfterUsedSet)
+			if err != nil {
+				return nil, err
+			}
+			e.Rows = append(e.Rows, row)
 		}
+	}
+
+	return e, nil
+}
+
+// Columns that aren't used are nil
+func (b *Binlog) deserializeRowImage(tableMap *TableMapEvent, usedSet bitset.Bitset) (RowImage, error) {
+	numberOfColumns := uint64(len(tableMap.ColumnTypes))
+
+	nullSet, err := ReadBitset(b.reader, countBits(usedSet, numberOfColumns))
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make(RowImage, numberOfColumns)
+	usedIndex := uint(0)
 
-		if uint64(len(tableMap.ColumnTypes)) != e.NumberOfColumns {
-			panic(fmt.Errorf("Table map does not contain expected number of column types %v %v", len(tableMap.ColumnTypes), e.NumberOfColumns))
+	for i := 0; i < int(numberOfColumns); i++ {
+		if !usedSet.Bit(uint(i)) {
+			continue
 		}
 
-		cells := make(RowImage, e.NumberOfColumns)
-		for i := 0; i < int(e.NumberOfColumns); i++ {
-			if e.UsedSet.Bit(uint(i)) {
-				if nullSet.Bit(uint(i)) {
-					cells[i] = NewNullRowImageCell(tableMap.ColumnTypes[i])
-				} else {
-					cells[i] = DeserializeRowImageCell(b.reader, tableMap, i)
-				}
-			} else {
-				cells[i] = nil
+		if nullSet.Bit(usedIndex) {
+			cells[i] = NewNullRowImageCell(tableMap.ColumnTypes[i])
+		} else {
+			cells[i], err = DeserializeRowImageCell(b.reader, tableMap, i)
+			if err != nil {
+				return nil, err
 			}
 		}
 
-		e.Rows = append(e.Rows, cells)
+		usedIndex++
 	}
 
-	return e
+	return cells, nil
 }
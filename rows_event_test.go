@@ -0,0 +1,162 @@
+package binlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRowsTableMap() *TableMapEvent {
+	varchar, _ := DeserializeColomnMetadata(bytes.NewReader([]byte{0x20, 0x00}), MYSQL_TYPE_VARCHAR)
+
+	return &TableMapEvent{
+		TableId:         42,
+		NumberOfColumns: 3,
+		ColumnTypes:     []MysqlType{MYSQL_TYPE_LONG, MYSQL_TYPE_VARCHAR, MYSQL_TYPE_LONG},
+		Metadata:        []*ColumnMetadata{nil, varchar, nil},
+	}
+}
+
+func testUpdateRowsEvent(t *testing.T, images ...byte) *RowsEvent {
+	body := append([]byte{
+		0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, // table id
+		0x00, 0x00, // reserved
+		0x02, 0x00, // extra info length
+		0x03, // number of columns
+	}, images...)
+
+	b, header := newTestBinlog(UPDATE_ROWS_EVENTv2, body)
+	b.TableMapCollection[42] = testRowsTableMap()
+
+	data, err := b.DeserializeRowsEvent(header)
+	assert.NoError(t, err)
+
+	return data.(*RowsEvent)
+}
+
+func TestDeserializeUpdateRowsEvent(t *testing.T) {
+	e := testUpdateRowsEvent(t,
+		0x07, 0x07, // used, after used
+		0x04, 0x01, 0x00, 0x00, 0x00, 0x01, 'a', // before: 1, "a", NULL
+		0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 'b', 0x05, 0x00, 0x00, 0x00, // after: 1, "b", 5
+	)
+
+	assert.True(t, e.IsUpdate())
+	assert.Len(t, e.Rows, 2)
+
+	updates := e.Updates()
+	assert.Len(t, updates, 1)
+	assert.Equal(t, NumberRowImageCell(1), updates[0].Before[0])
+	assert.Equal(t, NewNullRowImageCell(MYSQL_TYPE_LONG), updates[0].Before[2])
+	assert.Equal(t, NumberRowImageCell(5), updates[0].After[2])
+
+	assert.Equal(t, []int{1, 2}, updates[0].ChangedColumns())
+	assert.Equal(t, []ColumnChange{
+		{
+			Index:  1,
+			Before: BytesRowImageCell{Type: MYSQL_TYPE_VARCHAR, Data: []byte("a")},
+			After:  BytesRowImageCell{Type: MYSQL_TYPE_VARCHAR, Data: []byte("b")},
+		},
+		{Index: 2, Before: NewNullRowImageCell(MYSQL_TYPE_LONG), After: NumberRowImageCell(5)},
+	}, updates[0].Diff())
+}
+
+func TestDeserializeMinimalUpdateRowsEvent(t *testing.T) {
+	e := testUpdateRowsEvent(t,
+		0x01, 0x06, // before has the key, after has the changed columns
+		0x00, 0x01, 0x00, 0x00, 0x00, // before: 1
+		0x01, 0x07, 0x00, 0x00, 0x00, // after: NULL, 7
+		0x00, 0x02, 0x00, 0x00, 0x00, // before: 2
+		0x00, 0x01, 'c', 0x08, 0x00, 0x00, 0x00, // after: "c", 8
+	)
+
+	updates := e.Updates()
+	assert.Len(t, updates, 2)
+
+	assert.Equal(t, RowImage{nil, NewNullRowImageCell(MYSQL_TYPE_VARCHAR), NumberRowImageCell(7)}, updates[0].After)
+	assert.Equal(t, []ColumnChange{
+		{Index: 1, After: NewNullRowImageCell(MYSQL_TYPE_VARCHAR)},
+		{Index: 2, After: NumberRowImageCell(7)},
+	}, updates[0].Diff())
+
+	assert.Equal(t, NumberRowImageCell(2), updates[1].Before[0])
+	assert.Equal(t, NumberRowImageCell(8), updates[1].After[2])
+}
+
+func TestUpdatesOnlyForUpdateEvents(t *testing.T) {
+	e := &RowsEvent{Type: WRITE_ROWS_EVENTv2, Rows: []RowImage{{NumberRowImageCell(1)}}}
+	assert.Nil(t, e.Updates())
+}
+
+// A MySQL 5.5 log (no checksums) with one WRITE_ROWS_EVENTv2 whose
+// last value is an empty VARCHAR
+func testEmptyStringBinlog() []byte {
+	serialize := func(eventType MysqlBinlogEventType, position int, body []byte) []byte {
+		length := EVENT_HEADER_LENGTH + len(body)
+
+		event := make([]byte, EVENT_HEADER_LENGTH)
+		event[EVENT_TYPE_OFFSET] = byte(eventType)
+		binary.LittleEndian.PutUint32(event[EVENT_LEN_OFFSET:], uint32(length))
+		binary.LittleEndian.PutUint32(event[EVENT_NEXT_OFFSET:], uint32(position+length))
+
+		return append(event, body...)
+	}
+
+	fdeBody := []byte{0x04, 0x00}
+	fdeBody = append(fdeBody, []byte("5.5.40-log")...)
+	fdeBody = append(fdeBody, make([]byte, SERVER_VERSION_LENGTH-len("5.5.40-log"))...)
+	fdeBody = append(fdeBody, 0x00, 0x00, 0x00, 0x00, byte(EVENT_HEADER_LENGTH))
+	fdeBody = append(fdeBody, defaultFormatDescription().PostHeaderLengths...)
+
+	tableMapBody := []byte{
+		0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, // table id
+		0x00, 0x00, // reserved
+		0x02, 'd', 'b', 0x00,
+		0x01, 't', 0x00,
+		0x01, // number of columns
+		byte(MYSQL_TYPE_VARCHAR),
+		0x02,       // metadata length
+		0x20, 0x00, // VARCHAR(32)
+		0x01, // can be null
+	}
+
+	rowsBody := []byte{
+		0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, // table id
+		0x00, 0x00, // reserved
+		0x02, 0x00, // extra info length
+		0x01, // number of columns
+		0x01, // used
+		0x00, // null bitmap
+		0x00, // ""
+	}
+
+	log := append([]byte{}, BINLOG_MAGIC[:]...)
+	log = append(log, serialize(FORMAT_DESCRIPTION_EVENT, len(log), fdeBody)...)
+	log = append(log, serialize(TABLE_MAP_EVENT, len(log), tableMapBody)...)
+	return append(log, serialize(WRITE_ROWS_EVENTv2, len(log), rowsBody)...)
+}
+
+func TestDeserializeEmptyLastValue(t *testing.T) {
+	log := testEmptyStringBinlog()
+	expected := RowImage{BytesRowImageCell{Type: MYSQL_TYPE_VARCHAR, Data: []byte{}}}
+
+	b, err := NewBinlog(bytes.NewReader(log))
+	assert.NoError(t, err)
+
+	n := NewNetworkBinlog(&testPacketReader{packets: testEventPackets(log)})
+
+	for _, reader := range []EventReader{b, n} {
+		var event *Event
+		for event == nil || event.Type() != WRITE_ROWS_EVENTv2 {
+			event, err = reader.Next(context.Background())
+			assert.NoError(t, err)
+		}
+
+		data, err := event.Data()
+		assert.NoError(t, err)
+		assert.Equal(t, []RowImage{expected}, data.(*RowsEvent).Rows)
+	}
+}
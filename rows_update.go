@@ -0,0 +1,78 @@
+package binlog
+
+import (
+	"reflect"
+)
+
+// One row changed by an UPDATE
+type RowsUpdate struct {
+	Before RowImage
+	After  RowImage
+}
+
+// Before/after pairs of an UPDATE event, nil for other rows events
+func (e *RowsEvent) Updates() []RowsUpdate {
+	if !e.IsUpdate() {
+		return nil
+	}
+
+	updates := make([]RowsUpdate, 0, len(e.Rows)/2)
+	for i := 0; i+1 < len(e.Rows); i += 2 {
+		updates = append(updates, RowsUpdate{
+			Before: e.Rows[i],
+			After:  e.Rows[i+1],
+		})
+	}
+
+	return updates
+}
+
+// Before is nil when the column is not in the before image
+// (binlog_row_image=MINIMAL only logs the primary key there)
+type ColumnChange struct {
+	Index  int
+	Before Value
+	After  Value
+}
+
+// Columns in the after image whose value differs from the before
+// image, in column order. Columns missing from the after image are
+// unchanged.
+func (u RowsUpdate) Diff() []ColumnChange {
+	changes := []ColumnChange{}
+
+	for i, after := range u.After {
+		if after == nil {
+			continue
+		}
+
+		var before Value
+		if i < len(u.Before) {
+			before = u.Before[i]
+		}
+
+		if before != nil && reflect.DeepEqual(before, after) {
+			continue
+		}
+
+		changes = append(changes, ColumnChange{
+			Index:  i,
+			Before: before,
+			After:  after,
+		})
+	}
+
+	return changes
+}
+
+// Just the indexes from Diff
+func (u RowsUpdate) ChangedColumns() []int {
+	changes := u.Diff()
+
+	indexes := make([]int, len(changes))
+	for i, change := range changes {
+		indexes[i] = change.Index
+	}
+
+	return indexes
+}
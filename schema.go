@@ -0,0 +1,160 @@
+package binlog
+
+import (
+	"fmt"
+
+	"github.com/granicus/mysql-binlog-go/charset"
+)
+
+/*
+SCHEMAS
+=======
+
+Binlogs don't carry everything about a table. Unless the server
+writes optional table map metadata (MySQL 8 binlog_row_metadata),
+things like column names and ENUM/SET members are missing. A
+SchemaProvider can fill those in, e.g. from information_schema or
+from a copy of the table definitions.
+
+*/
+
+type ColumnSchema struct {
+	Name       string
+	Charset    string // e.g. "utf8mb4" or "binary", see the charset package
+	Unsigned   bool
+	EnumValues []string
+	SetValues  []string
+}
+
+type TableSchema struct {
+	Columns []ColumnSchema
+}
+
+type SchemaProvider interface {
+	// Should return nil (and no error) for unknown tables
+	TableSchema(databaseName, tableName string) (*TableSchema, error)
+}
+
+// SchemaProvider for a fixed set of tables, keyed by "database.table"
+type SchemaMap map[string]*TableSchema
+
+func (m SchemaMap) TableSchema(databaseName, tableName string) (*TableSchema, error) {
+	return m[databaseName+"."+tableName], nil
+}
+
+// Schemas are looked up as TABLE_MAP_EVENTs are decoded
+func (b *Binlog) SetSchemaProvider(provider SchemaProvider) {
+	b.schemaProvider = provider
+}
+
+func (b *Binlog) lookupTableSchema(e *TableMapEvent) error {
+	if b.schemaProvider == nil {
+		return nil
+	}
+
+	schema, err := b.schemaProvider.TableSchema(e.DatabaseName, e.TableName)
+	if err != nil {
+		return fmt.Errorf("schema for %v.%v: %w", e.DatabaseName, e.TableName, err)
+	}
+
+	if schema != nil && uint64(len(schema.Columns)) != e.NumberOfColumns {
+		return fmt.Errorf("%w: schema for %v.%v has %v columns, table map has %v",
+			ErrSchemaMismatch, e.DatabaseName, e.TableName, len(schema.Columns), e.NumberOfColumns)
+	}
+
+	e.Schema = schema
+	return nil
+}
+
+func (e *TableMapEvent) columnSchema(columnIndex int) *ColumnSchema {
+	if e.Schema == nil || columnIndex >= len(e.Schema.Columns) {
+		return nil
+	}
+
+	return &e.Schema.Columns[columnIndex]
+}
+
+/*
+The accessors below prefer the table map's optional metadata and
+fall back to the schema.
+*/
+
+func (e *TableMapEvent) hasOptionalMetadata(field OptionalMetadataType) bool {
+	return e.OptionalMetadata != nil && e.OptionalMetadata.Has(field)
+}
+
+// Column name, "" if unknown
+func (e *TableMapEvent) ColumnName(columnIndex int) string {
+	if e.hasOptionalMetadata(COLUMN_NAME) {
+		return e.OptionalMetadata.ColumnNames[columnIndex]
+	}
+
+	if column := e.columnSchema(columnIndex); column != nil {
+		return column.Name
+	}
+
+	return ""
+}
+
+// Column indexes of the primary key, nil if unknown
+func (e *TableMapEvent) PrimaryKey() []uint64 {
+	if e.hasOptionalMetadata(SIMPLE_PRIMARY_KEY) || e.hasOptionalMetadata(PRIMARY_KEY_WITH_PREFIX) {
+		return e.OptionalMetadata.PrimaryKey
+	}
+
+	return nil
+}
+
+// Character set of a string column, "" if unknown
+func (e *TableMapEvent) ColumnCharset(columnIndex int) string {
+	if e.OptionalMetadata != nil && e.OptionalMetadata.ColumnCharsets != nil {
+		if collationId := e.OptionalMetadata.ColumnCharsets[columnIndex]; collationId != 0 {
+			return charset.CollationCharset(collationId)
+		}
+	}
+
+	if column := e.columnSchema(columnIndex); column != nil {
+		return column.Charset
+	}
+
+	return ""
+}
+
+// Whether a numeric column is UNSIGNED, false if unknown
+func (e *TableMapEvent) IsUnsigned(columnIndex int) bool {
+	if e.hasOptionalMetadata(SIGNEDNESS) {
+		return e.OptionalMetadata.Unsigned[columnIndex]
+	}
+
+	if column := e.columnSchema(columnIndex); column != nil {
+		return column.Unsigned
+	}
+
+	return false
+}
+
+// Members of an ENUM column, nil if unknown
+func (e *TableMapEvent) EnumValues(columnIndex int) []string {
+	if e.hasOptionalMetadata(ENUM_STR_VALUE) {
+		return e.OptionalMetadata.EnumValues[columnIndex]
+	}
+
+	if column := e.columnSchema(columnIndex); column != nil {
+		return column.EnumValues
+	}
+
+	return nil
+}
+
+// Members of a SET column, nil if unknown
+func (e *TableMapEvent) SetValues(columnIndex int) []string {
+	if e.hasOptionalMetadata(SET_STR_VALUE) {
+		return e.OptionalMetadata.SetValues[columnIndex]
+	}
+
+	if column := e.columnSchema(columnIndex); column != nil {
+		return column.SetValues
+	}
+
+	return nil
+}
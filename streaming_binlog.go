@@ -1,63 +1,245 @@
 package binlog
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// How often to check for the next file after a ROTATE_EVENT
+// (MySQL writes the rotate event before creating the new file)
+const ROTATE_POLL_INTERVAL = 100 * time.Millisecond
+
 // TODO: find a way not to have two different references to the AppendableBuffer
 type StreamingBinlog struct {
 	Binlog
-	filepath   string
-	listening  bool
-	buffer     *AppendableBuffer
-	appendFunc func([]byte)
-	stopChan   chan bool
-	tailer     *BinlogTailer
+	filepath string
+	buffer   *AppendableBuffer
+	tailer   *BinlogTailer
+
+	// See SetRetention
+	retention int64
+
+	// A ROTATE_EVENT whose next file we stopped waiting for
+	rotating *Event
 }
 
-func StreamBinlog(filepath string, preloadBufferStopPosition int64) *StreamingBinlog {
+func newStreamingBinlog(path string) (*StreamingBinlog, error) {
 	var err error
 	log := new(StreamingBinlog)
 
 	log.TableMapCollection = make(map[uint64]*TableMapEvent)
+	log.formatDescription = defaultFormatDescription()
 	log.bytesLength = -1
 	log.events = []*Event{}
-	log.filepath = filepath
+	log.filepath = path
+	log.name = filepath.Base(path)
+	log.retention = DEFAULT_RETENTION
+
+	log.tailer, err = Tail(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Positions in the buffer line up with positions in the file
+	log.buffer = NewAppendableBuffer(append([]byte{}, BINLOG_MAGIC[:]...))
+	log.reader = log.buffer // set Binlog buffer
+	log.nextEventPosition = int64(MAGIC_BYTES_LENGTH)
+
+	return log, nil
+}
 
-	log.tailer, err = Tail(filepath)
+// Follows a binlog file as it is written. Events up to
+// preloadBufferStopPosition are read before returning.
+func StreamBinlog(filepath string, preloadBufferStopPosition int64) (*StreamingBinlog, error) {
+	log, err := newStreamingBinlog(filepath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	filePosition := int64(MAGIC_BYTES_LENGTH)
-	existingContents := BINLOG_MAGIC[:]
+	for log.tailer.Position() < preloadBufferStopPosition {
+		serializedEvent, err := log.tailer.ReadSerializedEvent(context.Background())
+		if err != nil {
+			log.tailer.Close()
+			return nil, err
+		}
 
-	for filePosition < preloadBufferStopPosition {
-		serializedEvent := log.tailer.ReadSerializedEvent()
-		filePosition += int64(len(serializedEvent))
-		existingContents = append(existingContents, serializedEvent...)
+		log.buffer.Append(serializedEvent)
 	}
 
-	log.buffer = NewAppendableBuffer(existingContents)
-	log.reader = log.buffer // set Binlog buffer
+	return log, nil
+}
+
+// Follows position.File in dir as it is written, Next starts with
+// the event at position.Offset (see position.go). Only what's needed
+// from the events before it is kept.
+func StreamBinlogAt(dir string, position Position) (*StreamingBinlog, error) {
+	path := filepath.Join(dir, position.File)
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if position.Offset > stat.Size() {
+		return nil, fmt.Errorf("%w: offset %v is past the end of %v", ErrInvalidPosition, position.Offset, path)
+	}
+
+	log, err := newStreamingBinlog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = log.skipTo(position.Offset, func() error {
+		serializedEvent, err := log.tailer.ReadSerializedEvent(context.Background())
+		if err != nil {
+			return err
+		}
+
+		log.buffer.Discard(log.nextEventPosition)
+		log.buffer.Append(serializedEvent)
+		return nil
+	})
 
-	log.Skip(int64(MAGIC_BYTES_LENGTH))
-	log.indexEvents()
+	if err != nil {
+		log.tailer.Close()
+		return nil, err
+	}
+
+	return log, nil
+}
 
-	return log
+// Where the event after the last one Next returned starts, i.e.
+// where to pick up from with StreamBinlogAt
+func (log *StreamingBinlog) Position() Position {
+	return Position{
+		File:   log.name,
+		Offset: log.nextOffset(),
+	}
+}
+
+// Path of the file currently being followed
+func (log *StreamingBinlog) File() string {
+	return log.filepath
 }
 
 func (log *StreamingBinlog) Close() {
 	log.tailer.Close()
 }
 
-func (log *StreamingBinlog) ReadEvent() *Event {
-	eventPosition := int64(log.buffer.Length())
+// How many bytes of events to hold on to after Next has returned
+// them (DEFAULT_RETENTION unless set). A negative retention keeps
+// every event, the way a Binlog does.
+func (log *StreamingBinlog) SetRetention(retention int64) {
+	log.retention = retention
+}
 
-	serializedEvent := log.tailer.ReadSerializedEvent()
-	log.buffer.Append(serializedEvent)
+// Returns the preloaded events and then waits for new ones as they
+// are written, until ctx is done.
+//
+// When a ROTATE_EVENT is read, the current file is closed and reading
+// continues transparently from the start of the next file. The rotate
+// event itself is still returned so the caller can see the new file
+// and position.
+// Events from the previous file that haven't been decoded are
+// released: their Data() fails with ErrEventReleased.
+//
+// If the file is truncated, replaced or deleted while it is being
+// followed, Next returns a *TailError (see binlog_tailer.go).
+func (log *StreamingBinlog) Next(ctx context.Context) (*Event, error) {
+	log.releaseEvents(log.buffer, log.retention)
+
+	if log.rotating != nil {
+		return log.rotate(ctx, log.rotating)
+	}
+
+	event, err := log.Binlog.Next(ctx)
+	for err == io.EOF {
+		serializedEvent, tailErr := log.tailer.ReadSerializedEvent(ctx)
+		if tailErr != nil {
+			return nil, tailErr
+		}
+
+		log.buffer.Append(serializedEvent)
+		event, err = log.Binlog.Next(ctx)
+	}
 
-	err := log.SetPosition(eventPosition)
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+
+	if event.Type() == ROTATE_EVENT {
+		return log.rotate(ctx, event)
+	}
+
+	return event, nil
+}
+
+// Returns the rotate event once the next file is open
+func (log *StreamingBinlog) rotate(ctx context.Context, event *Event) (*Event, error) {
+	log.rotating = event
+
+	if err := log.openNextFile(ctx, event); err != nil {
+		if err == ctx.Err() {
+			return nil, err
+		}
+
+		return nil, newEventError(event.Position(), event.Type(), err)
 	}
 
-	log.indexEvent()
-	return log.events[len(log.events)-1]
+	log.rotating = nil
+	return event, nil
+}
+
+func (log *StreamingBinlog) openNextFile(ctx context.Context, event *Event) error {
+	// Decoded before the buffer it lives in is replaced
+	data, err := event.Data()
+	if err != nil {
+		return err
+	}
+
+	rotateEvent := data.(*RotateEvent)
+	nextFilepath := filepath.Join(filepath.Dir(log.filepath), rotateEvent.NextFile)
+
+	for {
+		_, err = os.Stat(nextFilepath)
+		if err == nil {
+			break
+		}
+
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(ROTATE_POLL_INTERVAL):
+		}
+	}
+
+	tailer, err := Tail(nextFilepath)
+	if err != nil {
+		return err
+	}
+
+	log.tailer.Close()
+	log.tailer = tailer
+	log.filepath = nextFilepath
+	log.name = filepath.Base(nextFilepath)
+
+	log.buffer = NewAppendableBuffer(append([]byte{}, BINLOG_MAGIC[:]...))
+	log.reader = log.buffer
+	log.events = []*Event{}
+	log.nextEventIndex = 0
+	log.nextEventPosition = int64(MAGIC_BYTES_LENGTH)
+
+	// Positions start over, MySQL doesn't split transactions
+	// across files
+	log.unholdEvents()
+
+	return nil
 }
@@ -0,0 +1,92 @@
+package binlog
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamingBinlogRotate(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "mysql-bin.000001")
+	second := filepath.Join(dir, "mysql-bin.000002")
+
+	writeTestFile(t, first, testChecksummedBinlog())
+
+	log, err := StreamBinlog(first, 0)
+	assert.NoError(t, err)
+	defer log.Close()
+
+	event, err := log.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, FORMAT_DESCRIPTION_EVENT, event.Type())
+
+	// The next file doesn't exist yet
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = log.Next(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	writeTestFile(t, second, testBinlogPreamble())
+
+	event, err = log.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ROTATE_EVENT, event.Type())
+	assert.Equal(t, second, log.File())
+
+	event, err = log.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, FORMAT_DESCRIPTION_EVENT, event.Type())
+	assert.Equal(t, int64(MAGIC_BYTES_LENGTH), event.Position())
+}
+
+func TestStreamingBinlogRotateReleasesEvents(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "mysql-bin.000001")
+	second := filepath.Join(dir, "mysql-bin.000002")
+
+	log := testBinlogPreamble()
+	log = append(log, serializeTestEvent(ROWS_QUERY_EVENT, len(log), 0, []byte("\x18INSERT INTO t VALUES (1)"))...)
+
+	rotateBody := []byte{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	rotateBody = append(rotateBody, []byte("mysql-bin.000002")...)
+	log = append(log, serializeTestEvent(ROTATE_EVENT, len(log), 0, rotateBody)...)
+
+	writeTestFile(t, first, log)
+	writeTestFile(t, second, testChecksummedBinlog())
+
+	s, err := StreamBinlog(first, 0)
+	assert.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Next(context.Background())
+	assert.NoError(t, err)
+
+	// Not decoded before the rotation
+	rowsQuery, err := s.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ROWS_QUERY_EVENT, rowsQuery.Type())
+
+	rotate, err := s.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ROTATE_EVENT, rotate.Type())
+	assert.Equal(t, second, s.File())
+
+	// Offsets in the first file now land in the second one
+	_, err = rowsQuery.Data()
+	assert.ErrorIs(t, err, ErrEventReleased)
+
+	_, err = rotate.Data()
+	assert.NoError(t, err)
+
+	event, err := s.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, FORMAT_DESCRIPTION_EVENT, event.Type())
+
+	_, err = event.Data()
+	assert.NoError(t, err)
+}
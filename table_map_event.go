@@ -1,7 +1,6 @@
 package binlog
 
 import (
-	"encoding/binary"
 	"fmt"
 
 	"github.com/granicus/mysql-binlog-go/bitset"
@@ -9,21 +8,23 @@ import (
 )
 
 type TableMapEvent struct {
-	TableId         uint64
-	DatabaseName    string
-	TableName       string
-	NumberOfColumns uint64
-	ColumnTypes     []MysqlType
-	Metadata        []*ColumnMetadata
-	CanBeNull       bitset.Bitset
+	TableId          uint64
+	DatabaseName     string
+	TableName        string
+	NumberOfColumns  uint64
+	ColumnTypes      []MysqlType
+	Metadata         []*ColumnMetadata
+	CanBeNull        bitset.Bitset
+	OptionalMetadata *TableMapOptionalMetadata // nil unless the server wrote any
+	Schema           *TableSchema              // nil unless a SchemaProvider knows the table
 }
 
 /*
 TABLE MAP DATA
 ==============
 
-Fixed:
-6 bytes = table id
+Fixed (post-header):
+6 bytes = table id (4 bytes if the post-header length is 6)
 2 bytes = reserved (skip)
 
 Let:
@@ -46,37 +47,58 @@ C bytes   = column types
 P bytes   = metdata length
 M bytes   = metadata
 N bytes   = can be null bitset
+O bytes   = optional metadata (MySQL 8, see table_map_optional_metadata.go)
 
 */
 
-func (b *Binlog) DeserializeTableMapEvent(header *EventHeader) EventData {
+func (b *Binlog) DeserializeTableMapEvent(header *EventHeader) (EventData, error) {
 	e := new(TableMapEvent)
 
-	// Read 6 bytes for table id, pad to 8, read as uint64
-	tableIdBytes, err := ReadBytes(b.reader, 6)
-	fatalErr(err)
-	e.TableId = binary.LittleEndian.Uint64(append(tableIdBytes, byte(0), byte(0)))
+	tableId, tableIdLength, err := b.readTableId(header.Type)
+	if err != nil {
+		return nil, err
+	}
+	e.TableId = tableId
+
+	// Skip 2 reserved bytes and anything newer in the post-header
+	if _, err = b.reader.Seek(2, 1); err != nil {
+		return nil, err
+	}
 
-	// Skip 2 reserved and 1 database name length bytes
-	_, err = b.reader.Seek(3, 1)
-	fatalErr(err)
+	if err = b.skipPostHeader(header.Type, tableIdLength+2); err != nil {
+		return nil, err
+	}
+
+	// Skip database name length
+	if _, err = b.reader.Seek(1, 1); err != nil {
+		return nil, err
+	}
 
 	e.DatabaseName, err = ReadNullTerminatedString(b.reader)
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	// Skip table name length
-	_, err = b.reader.Seek(1, 1)
-	fatalErr(err)
+	if _, err = b.reader.Seek(1, 1); err != nil {
+		return nil, err
+	}
 
 	e.TableName, err = ReadNullTerminatedString(b.reader)
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	e.NumberOfColumns, err = ReadPackedInteger(b.reader)
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	// Read column types as bytes and convert them to MysqlTypes
 	columnTypesBytes, err := ReadBytes(b.reader, int(e.NumberOfColumns))
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	e.ColumnTypes = make([]MysqlType, len(columnTypesBytes))
 	for i, b := range columnTypesBytes {
@@ -84,28 +106,61 @@ func (b *Binlog) DeserializeTableMapEvent(header *EventHeader) EventData {
 	}
 
 	metadataLength, err := ReadPackedInteger(b.reader)
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	preMetadataPosition, err := b.reader.Seek(0, 1)
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	e.Metadata = make([]*ColumnMetadata, len(e.ColumnTypes))
 	for i, t := range e.ColumnTypes {
-		e.Metadata[i] = DeserializeColomnMetadata(b.reader, t)
+		e.Metadata[i], err = DeserializeColomnMetadata(b.reader, t)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	postMetadataPosition, err := b.reader.Seek(0, 1)
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
 
 	if postMetadataPosition-preMetadataPosition != int64(metadataLength) {
-		panic(fmt.Sprintf("Overshot metadata length by %v", postMetadataPosition-preMetadataPosition-int64(metadataLength)))
+		return nil, fmt.Errorf("%w: read %v bytes of column metadata, expected %v",
+			ErrMetadataLengthMismatch, postMetadataPosition-preMetadataPosition, metadataLength)
 	}
 
 	e.CanBeNull, err = ReadBitset(b.reader, int(e.NumberOfColumns))
-	fatalErr(err)
+	if err != nil {
+		return nil, err
+	}
+
+	optionalMetadataLength, err := b.remainingEventLength(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if optionalMetadataLength > 0 {
+		optionalMetadata, err := ReadBytes(b.reader, int(optionalMetadataLength))
+		if err != nil {
+			return nil, err
+		}
+
+		e.OptionalMetadata, err = deserializeTableMapOptionalMetadata(optionalMetadata, e)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = b.lookupTableSchema(e); err != nil {
+		return nil, err
+	}
 
 	// Insert into tableMapCollectionInstance
 	b.TableMapCollection[e.TableId] = e
 
-	return e
+	return e, nil
 }
@@ -0,0 +1,106 @@
+package binlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeserializeTableMapEventOptionalMetadata(t *testing.T) {
+	body := []byte{
+		0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, // table id
+		0x00, 0x00, // reserved
+		0x02, 'd', 'b', 0x00,
+		0x01, 't', 0x00,
+		0x04, // number of columns
+		byte(MYSQL_TYPE_LONG), byte(MYSQL_TYPE_VARCHAR), byte(MYSQL_TYPE_STRING), byte(MYSQL_TYPE_TINY),
+		0x04,       // metadata length
+		0x40, 0x00, // VARCHAR(64)
+		byte(MYSQL_TYPE_ENUM), 0x01,
+		0x0f, // can be null
+
+		// SIGNEDNESS: LONG unsigned, TINY signed
+		0x01, 0x01, 0x80,
+		// DEFAULT_CHARSET: utf8mb4_general_ci
+		0x02, 0x01, 0x2d,
+		// ENUM_AND_SET_DEFAULT_CHARSET: latin1, ENUM column uses binary
+		0x0a, 0x03, 0x08, 0x00, 0x3f,
+		// COLUMN_NAME
+		0x04, 0x0e, 0x02, 'i', 'd', 0x04, 'n', 'a', 'm', 'e', 0x01, 'e', 0x03, 'a', 'g', 'e',
+		// ENUM_STR_VALUE
+		0x06, 0x05, 0x02, 0x01, 'a', 0x01, 'b',
+		// SIMPLE_PRIMARY_KEY
+		0x08, 0x01, 0x00,
+		// COLUMN_VISIBILITY: all but the last
+		0x0c, 0x01, 0xe0,
+		// unknown field
+		0x7f, 0x02, 0x00, 0x00,
+	}
+
+	b, header := newTestBinlog(TABLE_MAP_EVENT, body)
+
+	data, err := b.DeserializeTableMapEvent(header)
+	assert.NoError(t, err)
+
+	e := data.(*TableMapEvent)
+	m := e.OptionalMetadata
+	assert.NotNil(t, m)
+
+	assert.Equal(t, []bool{true, false, false, false}, m.Unsigned)
+	assert.Equal(t, uint64(45), m.DefaultCharset)
+	assert.Equal(t, uint64(8), m.EnumAndSetDefaultCharset)
+	assert.Equal(t, []uint64{0, 45, 63, 0}, m.ColumnCharsets)
+	assert.Equal(t, []bool{true, true, true, false}, m.Visible)
+	assert.False(t, m.Has(SET_STR_VALUE))
+
+	assert.True(t, e.IsUnsigned(0))
+	assert.False(t, e.IsUnsigned(3))
+	assert.Equal(t, "name", e.ColumnName(1))
+	assert.Equal(t, []string{"a", "b"}, e.EnumValues(2))
+	assert.Nil(t, e.SetValues(2))
+	assert.Equal(t, []uint64{0}, e.PrimaryKey())
+}
+
+func TestDeserializeTableMapEventWithoutOptionalMetadata(t *testing.T) {
+	body := []byte{
+		0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, // table id
+		0x00, 0x00, // reserved
+		0x02, 'd', 'b', 0x00,
+		0x01, 't', 0x00,
+		0x01,                  // number of columns
+		byte(MYSQL_TYPE_LONG), // column types
+		0x00,                  // metadata length
+		0x00,                  // can be null
+	}
+
+	b, header := newTestBinlog(TABLE_MAP_EVENT, body)
+	b.SetSchemaProvider(SchemaMap{"db.t": &TableSchema{Columns: []ColumnSchema{{Name: "id", Unsigned: true}}}})
+
+	data, err := b.DeserializeTableMapEvent(header)
+	assert.NoError(t, err)
+
+	e := data.(*TableMapEvent)
+	assert.Nil(t, e.OptionalMetadata)
+	assert.Equal(t, "id", e.ColumnName(0))
+	assert.True(t, e.IsUnsigned(0))
+	assert.Nil(t, e.PrimaryKey())
+}
+
+func TestDeserializeTableMapEventTruncatedOptionalMetadata(t *testing.T) {
+	body := []byte{
+		0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, // table id
+		0x00, 0x00, // reserved
+		0x02, 'd', 'b', 0x00,
+		0x01, 't', 0x00,
+		0x01,                  // number of columns
+		byte(MYSQL_TYPE_LONG), // column types
+		0x00,                  // metadata length
+		0x00,                  // can be null
+		0x04, 0x05, 0x02, 'i', // COLUMN_NAME shorter than its length
+	}
+
+	b, header := newTestBinlog(TABLE_MAP_EVENT, body)
+
+	_, err := b.DeserializeTableMapEvent(header)
+	assert.Error(t, err)
+}
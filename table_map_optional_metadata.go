@@ -0,0 +1,340 @@
+package binlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	. "github.com/granicus/mysql-binlog-go/deserialization"
+)
+
+/*
+TABLE MAP OPTIONAL METADATA
+===========================
+
+MySQL 8 (binlog_row_metadata=MINIMAL or FULL) appends a list of
+fields to TABLE_MAP_EVENT after the can be null bitset:
+
+1 byte  = field type
+P bytes = field length (packed int)
+L bytes = field value
+
+Most fields only cover a subset of the columns, in column order:
+
+numeric columns   = TINY, SHORT, INT24, LONG, LONGLONG, FLOAT, DOUBLE, NEWDECIMAL
+character columns = VARCHAR, BLOB, VAR_STRING and STRING (except ENUM/SET)
+enum/set columns  = STRING with an ENUM/SET real type
+geometry columns  = GEOMETRY
+
+Fields:
+SIGNEDNESS                   = bitmap (MSB first), 1 bit per numeric column
+DEFAULT_CHARSET              = packed default collation, then packed
+                               (character column index, collation) pairs
+                               for columns that don't use the default
+COLUMN_CHARSET               = packed collation per character column
+COLUMN_NAME                  = (packed length, name) per column
+SET_STR_VALUE                = per SET column: packed count, then
+                               (packed length, string) per member
+ENUM_STR_VALUE               = same as SET_STR_VALUE, for ENUM columns
+GEOMETRY_TYPE                = packed geometry type per geometry column
+SIMPLE_PRIMARY_KEY           = packed column index per key column
+PRIMARY_KEY_WITH_PREFIX      = packed (column index, prefix length) pairs
+ENUM_AND_SET_DEFAULT_CHARSET = DEFAULT_CHARSET, for enum/set columns
+ENUM_AND_SET_COLUMN_CHARSET  = COLUMN_CHARSET, for enum/set columns
+COLUMN_VISIBILITY            = bitmap (MSB first), 1 bit per column
+
+Unknown fields are skipped. Everything below is exposed per column
+index (so e.g. ColumnCharsets[i] is 0 for a numeric column).
+
+*/
+
+type OptionalMetadataType byte
+
+const (
+	SIGNEDNESS OptionalMetadataType = iota + 1
+	DEFAULT_CHARSET
+	COLUMN_CHARSET
+	COLUMN_NAME
+	SET_STR_VALUE
+	ENUM_STR_VALUE
+	GEOMETRY_TYPE
+	SIMPLE_PRIMARY_KEY
+	PRIMARY_KEY_WITH_PREFIX
+	ENUM_AND_SET_DEFAULT_CHARSET
+	ENUM_AND_SET_COLUMN_CHARSET
+	COLUMN_VISIBILITY
+)
+
+type TableMapOptionalMetadata struct {
+	Unsigned                 []bool
+	DefaultCharset           uint64
+	EnumAndSetDefaultCharset uint64
+	ColumnCharsets           []uint64 // collation ids, character and enum/set columns only
+	ColumnNames              []string
+	SetValues                [][]string
+	EnumValues               [][]string
+	GeometryTypes            []uint64
+	PrimaryKey               []uint64 // column indexes
+	PrimaryKeyPrefixes       []uint64 // same length as PrimaryKey, 0 means the whole column
+	Visible                  []bool
+
+	// Which fields were present
+	fields map[OptionalMetadataType]bool
+}
+
+func (m *TableMapOptionalMetadata) Has(field OptionalMetadataType) bool {
+	return m.fields[field]
+}
+
+// Kinds of column the optional metadata fields are indexed by
+func (e *TableMapEvent) realType(columnIndex int) MysqlType {
+	t := e.ColumnTypes[columnIndex]
+
+	if t == MYSQL_TYPE_STRING && e.Metadata[columnIndex] != nil {
+		return e.Metadata[columnIndex].RealType()
+	}
+
+	return t
+}
+
+func (e *TableMapEvent) isNumericColumn(columnIndex int) bool {
+	switch e.realType(columnIndex) {
+	case MYSQL_TYPE_TINY, MYSQL_TYPE_SHORT, MYSQL_TYPE_INT24, MYSQL_TYPE_LONG, MYSQL_TYPE_LONGLONG,
+		MYSQL_TYPE_FLOAT, MYSQL_TYPE_DOUBLE, MYSQL_TYPE_NEWDECIMAL:
+		return true
+	}
+
+	return false
+}
+
+func (e *TableMapEvent) isCharacterColumn(columnIndex int) bool {
+	switch e.realType(columnIndex) {
+	case MYSQL_TYPE_VARCHAR, MYSQL_TYPE_BLOB, MYSQL_TYPE_VAR_STRING, MYSQL_TYPE_STRING:
+		return true
+	}
+
+	return false
+}
+
+func (e *TableMapEvent) isEnumOrSetColumn(columnIndex int) bool {
+	t := e.realType(columnIndex)
+	return t == MYSQL_TYPE_ENUM || t == MYSQL_TYPE_SET
+}
+
+// Column indexes matching the predicate, in order
+func (e *TableMapEvent) columnsWhere(predicate func(int) bool) []int {
+	columns := []int{}
+	for i := range e.ColumnTypes {
+		if predicate(i) {
+			columns = append(columns, i)
+		}
+	}
+
+	return columns
+}
+
+func deserializeTableMapOptionalMetadata(data []byte, e *TableMapEvent) (*TableMapOptionalMetadata, error) {
+	m := &TableMapOptionalMetadata{
+		fields: make(map[OptionalMetadataType]bool),
+	}
+	r := bytes.NewReader(data)
+
+	for r.Len() > 0 {
+		fieldType, err := ReadByte(r)
+		if err != nil {
+			return nil, err
+		}
+
+		length, err := ReadPackedInteger(r)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := ReadBytes(r, int(length))
+		if err != nil {
+			return nil, err
+		}
+
+		field := OptionalMetadataType(fieldType)
+		if err = m.deserializeField(field, bytes.NewReader(value), e); err != nil {
+			return nil, fmt.Errorf("%w: optional metadata field %v: %v", ErrMalformedEvent, field, err)
+		}
+
+		m.fields[field] = true
+	}
+
+	return m, nil
+}
+
+func (m *TableMapOptionalMetadata) deserializeField(field OptionalMetadataType, r *bytes.Reader, e *TableMapEvent) error {
+	columnCount := len(e.ColumnTypes)
+	var err error
+
+	switch field {
+	case SIGNEDNESS:
+		m.Unsigned, err = readColumnBitmap(r, columnCount, e.columnsWhere(e.isNumericColumn))
+
+	case COLUMN_VISIBILITY:
+		all := e.columnsWhere(func(int) bool { return true })
+		m.Visible, err = readColumnBitmap(r, columnCount, all)
+
+	case DEFAULT_CHARSET, ENUM_AND_SET_DEFAULT_CHARSET:
+		columns := e.columnsWhere(e.isCharacterColumn)
+		if field == ENUM_AND_SET_DEFAULT_CHARSET {
+			columns = e.columnsWhere(e.isEnumOrSetColumn)
+		}
+
+		var defaultCharset uint64
+		defaultCharset, err = ReadPackedInteger(r)
+		if err != nil {
+			return err
+		}
+
+		if field == DEFAULT_CHARSET {
+			m.DefaultCharset = defaultCharset
+		} else {
+			m.EnumAndSetDefaultCharset = defaultCharset
+		}
+
+		m.ensureColumnCharsets(columnCount)
+		for _, column := range columns {
+			m.ColumnCharsets[column] = defaultCharset
+		}
+
+		for r.Len() > 0 {
+			index, err := ReadPackedInteger(r)
+			if err != nil {
+				return err
+			}
+
+			charset, err := ReadPackedInteger(r)
+			if err != nil {
+				return err
+			}
+
+			if index >= uint64(len(columns)) {
+				return fmt.Errorf("charset for column %v out of range", index)
+			}
+
+			m.ColumnCharsets[columns[index]] = charset
+		}
+
+	case COLUMN_CHARSET, ENUM_AND_SET_COLUMN_CHARSET:
+		columns := e.columnsWhere(e.isCharacterColumn)
+		if field == ENUM_AND_SET_COLUMN_CHARSET {
+			columns = e.columnsWhere(e.isEnumOrSetColumn)
+		}
+
+		m.ensureColumnCharsets(columnCount)
+		for _, column := range columns {
+			m.ColumnCharsets[column], err = ReadPackedInteger(r)
+			if err != nil {
+				return err
+			}
+		}
+
+	case COLUMN_NAME:
+		m.ColumnNames = make([]string, columnCount)
+		for i := range m.ColumnNames {
+			m.ColumnNames[i], err = readPackedLengthString(r)
+			if err != nil {
+				return err
+			}
+		}
+
+	case SET_STR_VALUE, ENUM_STR_VALUE:
+		columns := e.columnsWhere(func(i int) bool {
+			if field == SET_STR_VALUE {
+				return e.realType(i) == MYSQL_TYPE_SET
+			}
+
+			return e.realType(i) == MYSQL_TYPE_ENUM
+		})
+
+		values := make([][]string, columnCount)
+		for _, column := range columns {
+			count, err := ReadPackedInteger(r)
+			if err != nil {
+				return err
+			}
+
+			values[column] = make([]string, count)
+			for i := range values[column] {
+				values[column][i], err = readPackedLengthString(r)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if field == SET_STR_VALUE {
+			m.SetValues = values
+		} else {
+			m.EnumValues = values
+		}
+
+	case GEOMETRY_TYPE:
+		m.GeometryTypes = make([]uint64, columnCount)
+		for _, column := range e.columnsWhere(func(i int) bool { return e.ColumnTypes[i] == MYSQL_TYPE_GEOMETRY }) {
+			m.GeometryTypes[column], err = ReadPackedInteger(r)
+			if err != nil {
+				return err
+			}
+		}
+
+	case SIMPLE_PRIMARY_KEY, PRIMARY_KEY_WITH_PREFIX:
+		m.PrimaryKey = []uint64{}
+		m.PrimaryKeyPrefixes = []uint64{}
+
+		for r.Len() > 0 {
+			column, err := ReadPackedInteger(r)
+			if err != nil {
+				return err
+			}
+
+			var prefix uint64
+			if field == PRIMARY_KEY_WITH_PREFIX {
+				prefix, err = ReadPackedInteger(r)
+				if err != nil {
+					return err
+				}
+			}
+
+			m.PrimaryKey = append(m.PrimaryKey, column)
+			m.PrimaryKeyPrefixes = append(m.PrimaryKeyPrefixes, prefix)
+		}
+	}
+
+	// Unknown fields are ignored
+	return err
+}
+
+func (m *TableMapOptionalMetadata) ensureColumnCharsets(columnCount int) {
+	if m.ColumnCharsets == nil {
+		m.ColumnCharsets = make([]uint64, columnCount)
+	}
+}
+
+// Bits are MSB first and only cover the given columns
+func readColumnBitmap(r io.Reader, columnCount int, columns []int) ([]bool, error) {
+	b, err := ReadBytes(r, (len(columns)+7)/8)
+	if err != nil {
+		return nil, err
+	}
+
+	bits := make([]bool, columnCount)
+	for i, column := range columns {
+		bits[column] = b[i/8]&(0x80>>uint(i%8)) != 0
+	}
+
+	return bits, nil
+}
+
+func readPackedLengthString(r io.Reader) (string, error) {
+	length, err := ReadPackedInteger(r)
+	if err != nil {
+		return "", err
+	}
+
+	return ReadString(r, int(length))
+}
@@ -0,0 +1,257 @@
+package binlog
+
+import (
+	"context"
+	"strings"
+)
+
+/*
+TRANSACTIONS
+============
+
+Row based replication writes every transaction as:
+
+GTID_EVENT or ANONYMOUS_GTID_EVENT (only with GTIDs enabled)
+QUERY_EVENT "BEGIN"
+TABLE_MAP_EVENT(s) and rows events
+XID_EVENT (or a QUERY_EVENT "COMMIT" for non-transactional engines)
+
+DDL and other statements that can't be rolled back are written
+as a single QUERY_EVENT (after their GTID event, if any) and make
+up a transaction on their own.
+
+Events that aren't part of any transaction (FORMAT_DESCRIPTION_EVENT,
+ROTATE_EVENT, PREVIOUS_GTIDS_EVENT, ...) are skipped.
+
+*/
+
+// StartPosition and EndPosition are reader positions, like
+// Event.Position(): EndPosition is where the event after the
+// transaction starts.
+type Transaction struct {
+	Events        []*Event
+	StartPosition int64
+	EndPosition   int64
+	Timestamp     uint32
+	GTID          *GtidEvent // nil if GTIDs are disabled
+
+	// Missing its start (the reader began in the middle of it) or
+	// its end (the next transaction began before it was committed)
+	Incomplete bool
+}
+
+// Only the rows events of the transaction
+func (t *Transaction) RowsEvents() ([]*RowsEvent, error) {
+	rowsEvents := []*RowsEvent{}
+
+	for _, event := range t.Events {
+		if !isRowsEvent(event.Type()) {
+			continue
+		}
+
+		data, err := event.Data()
+		if err != nil {
+			return nil, err
+		}
+
+		rowsEvents = append(rowsEvents, data.(*RowsEvent))
+	}
+
+	return rowsEvents, nil
+}
+
+func isRowsEvent(eventType MysqlBinlogEventType) bool {
+	switch eventType {
+	case WRITE_ROWS_EVENTv0, UPDATE_ROWS_EVENTv0, DELETE_ROWS_EVENTv0,
+		WRITE_ROWS_EVENTv1, UPDATE_ROWS_EVENTv1, DELETE_ROWS_EVENTv1,
+		WRITE_ROWS_EVENTv2, UPDATE_ROWS_EVENTv2, DELETE_ROWS_EVENTv2:
+		return true
+	}
+
+	return false
+}
+
+type TransactionIterator struct {
+	events  EventReader
+	current *Transaction
+	begun   bool
+}
+
+// Groups the events of any EventReader into transactions
+func NewTransactionIterator(events EventReader) *TransactionIterator {
+	return &TransactionIterator{
+		events: events,
+	}
+}
+
+// Iterates over the transactions of the file from its start,
+// independently of Next. The iterator returns io.EOF after the
+// last complete transaction; an unfinished transaction at the end
+// of the file is not returned.
+func (b *Binlog) Transactions() *TransactionIterator {
+	i := 0
+
+	return NewTransactionIterator(EventReaderFunc(func(ctx context.Context) (*Event, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if i >= len(b.events) {
+			if _, err := b.readEvent(); err != nil {
+				return nil, err
+			}
+		}
+
+		i++
+		return b.events[i-1], nil
+	}))
+}
+
+// Iterates over the preloaded transactions and then waits for new
+// ones as they are written. This consumes the events, like Next.
+// The events of a transaction aren't released (see SetRetention)
+// before it has been returned, the next call to Next may release
+// them.
+func (log *StreamingBinlog) Transactions() *TransactionIterator {
+	return NewTransactionIterator(log)
+}
+
+// Waits for transactions from the server, consuming the events like
+// StreamingBinlog.Transactions
+func (n *NetworkBinlog) Transactions() *TransactionIterator {
+	return NewTransactionIterator(n)
+}
+
+// Errors from the event reader (io.EOF, ctx.Err(), ...) are
+// returned as they are
+func (it *TransactionIterator) Next(ctx context.Context) (*Transaction, error) {
+	for {
+		event, err := it.events.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := it.add(event)
+		if err != nil {
+			return nil, err
+		}
+
+		if t != nil {
+			return t, nil
+		}
+	}
+}
+
+// Starts a new transaction with event, returning the open one
+// (flagged incomplete) if there is one
+func (it *TransactionIterator) start(event *Event, header *EventHeader) *Transaction {
+	unfinished := it.current
+	if unfinished != nil {
+		unfinished.Incomplete = true
+		unfinished.EndPosition = event.Position()
+	}
+
+	it.current = &Transaction{
+		Events:        []*Event{},
+		StartPosition: event.Position(),
+		Timestamp:     header.Timestamp,
+	}
+	it.begun = false
+
+	if holder, ok := it.events.(eventHolder); ok {
+		holder.holdEvents(event.Position())
+	}
+
+	return unfinished
+}
+
+func (it *TransactionIterator) finish(event *Event, header *EventHeader) *Transaction {
+	t := it.current
+	t.Events = append(t.Events, event)
+	t.EndPosition = event.Position() + int64(header.Length)
+
+	it.current = nil
+	it.begun = false
+
+	if holder, ok := it.events.(eventHolder); ok {
+		holder.unholdEvents()
+	}
+
+	return t
+}
+
+// Adds the event to the current transaction, returns the transaction
+// it completed (or cut short), if any
+func (it *TransactionIterator) add(event *Event) (*Transaction, error) {
+	header, err := event.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	var unfinished *Transaction
+
+	switch event.Type() {
+	case GTID_EVENT, ANONYMOUS_GTID_EVENT:
+		data, err := event.Data()
+		if err != nil {
+			return nil, err
+		}
+
+		unfinished = it.start(event, header)
+		it.current.GTID = data.(*GtidEvent)
+
+	case QUERY_EVENT:
+		data, err := event.Data()
+		if err != nil {
+			return nil, err
+		}
+
+		query := strings.ToUpper(strings.TrimSpace(data.(*QueryEvent).Query))
+
+		if query == "BEGIN" {
+			if it.current == nil || it.begun {
+				unfinished = it.start(event, header)
+			}
+
+			it.begun = true
+			break
+		}
+
+		if it.current == nil {
+			it.start(event, header)
+
+			// The BEGIN was before the reader started
+			if query == "COMMIT" || query == "ROLLBACK" {
+				it.current.Incomplete = true
+			}
+		}
+
+		// Either COMMIT/ROLLBACK, or a statement that is its own transaction
+		if !it.begun || query == "COMMIT" || query == "ROLLBACK" {
+			return it.finish(event, header), nil
+		}
+
+	case XID_EVENT:
+		if it.current == nil {
+			it.start(event, header)
+			it.current.Incomplete = true
+		}
+
+		return it.finish(event, header), nil
+
+	case FORMAT_DESCRIPTION_EVENT, ROTATE_EVENT, STOP_EVENT, PREVIOUS_GTIDS_EVENT, HEARTBEAT_EVENT:
+		return nil, nil
+
+	default:
+		// Rows events and table maps of a transaction whose start
+		// was before the reader started
+		if it.current == nil {
+			it.start(event, header)
+			it.current.Incomplete = true
+			it.begun = true
+		}
+	}
+
+	it.current.Events = append(it.current.Events, event)
+	return unfinished, nil
+}
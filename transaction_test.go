@@ -0,0 +1,130 @@
+package binlog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testQueryEventBody(query string) []byte {
+	body := []byte{
+		0x01, 0x00, 0x00, 0x00, // thread id
+		0x00, 0x00, 0x00, 0x00, // execution time
+		0x02,       // database name length
+		0x00, 0x00, // error code
+		0x00, 0x00, // status vars length
+		'd', 'b', 0x00,
+	}
+
+	return append(body, []byte(query)...)
+}
+
+func TestTransactions(t *testing.T) {
+	gtidBody := []byte{0x01}
+	gtidBody = append(gtidBody, bytes.Repeat([]byte{0xab}, 16)...)
+	gtidBody = append(gtidBody, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+
+	log := testBinlogPreamble()
+	events := []struct {
+		eventType MysqlBinlogEventType
+		body      []byte
+	}{
+		{GTID_EVENT, gtidBody},
+		{QUERY_EVENT, testQueryEventBody("BEGIN")},
+		{XID_EVENT, []byte{0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{QUERY_EVENT, testQueryEventBody("CREATE TABLE t (id int)")},
+		{QUERY_EVENT, testQueryEventBody("BEGIN")},
+	}
+
+	positions := []int{}
+	for _, e := range events {
+		positions = append(positions, len(log))
+		log = append(log, serializeTestEvent(e.eventType, len(log), 0, e.body)...)
+	}
+
+	b, err := NewBinlog(bytes.NewReader(log))
+	assert.NoError(t, err)
+
+	it := b.Transactions()
+
+	first, err := it.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, first.Events, 3)
+	assert.Equal(t, int64(positions[0]), first.StartPosition)
+	assert.Equal(t, int64(positions[3]), first.EndPosition)
+	assert.Equal(t, "abababab-abab-abab-abab-abababababab:7", first.GTID.String())
+
+	xid, err := first.Events[2].Data()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), xid.(*XidEvent).Xid)
+
+	ddl, err := it.Next(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, ddl.Events, 1)
+	assert.Nil(t, ddl.GTID)
+
+	_, err = it.Next(context.Background())
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTransactionsIncomplete(t *testing.T) {
+	gtidBody := []byte{0x01}
+	gtidBody = append(gtidBody, bytes.Repeat([]byte{0xab}, 16)...)
+	gtidBody = append(gtidBody, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	xidBody := []byte{0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	log := testBinlogPreamble()
+	events := []struct {
+		eventType MysqlBinlogEventType
+		body      []byte
+	}{
+		// Started in the middle of a transaction
+		{WRITE_ROWS_EVENTv2, []byte{0x00}},
+		{XID_EVENT, xidBody},
+		// Never committed
+		{GTID_EVENT, gtidBody},
+		{QUERY_EVENT, testQueryEventBody("BEGIN")},
+		{GTID_EVENT, gtidBody},
+		{QUERY_EVENT, testQueryEventBody("BEGIN")},
+		{XID_EVENT, xidBody},
+	}
+
+	positions := []int64{}
+	for _, e := range events {
+		positions = append(positions, int64(len(log)))
+
+		// NextPosition is in some other file (as over the network),
+		// transactions shouldn't use it
+		log = append(log, serializeTestEvent(e.eventType, len(log)+1000, 0, e.body)...)
+	}
+	end := int64(len(log))
+
+	b, err := NewBinlog(bytes.NewReader(log))
+	assert.NoError(t, err)
+
+	it := b.Transactions()
+
+	orphaned, err := it.Next(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, orphaned.Incomplete)
+	assert.Len(t, orphaned.Events, 2)
+	assert.Equal(t, positions[0], orphaned.StartPosition)
+	assert.Equal(t, positions[2], orphaned.EndPosition)
+
+	uncommitted, err := it.Next(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, uncommitted.Incomplete)
+	assert.Len(t, uncommitted.Events, 2)
+	assert.Equal(t, positions[2], uncommitted.StartPosition)
+	assert.Equal(t, positions[4], uncommitted.EndPosition)
+
+	committed, err := it.Next(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, committed.Incomplete)
+	assert.Len(t, committed.Events, 3)
+	assert.Equal(t, positions[4], committed.StartPosition)
+	assert.Equal(t, end, committed.EndPosition)
+}
@@ -0,0 +1,266 @@
+package binlog
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+/*
+UNMARSHALLING ROWS
+==================
+
+UnmarshalRow copies a row image into a struct, matching columns to
+fields by their `binlog:"column_name"` tag. Fields without a tag
+(or tagged "-") are left alone, and so are fields whose column is
+not in the row image (e.g. columns missing from a minimal before
+image). Column names come from the table map's optional metadata
+or its schema.
+
+Supported field types:
+
+int*, uint*     = integer cells, plus ENUM index, SET and BIT
+float32/float64 = numeric cells
+bool            = integer cells, false for 0
+string          = any cell (text is decoded from its charset)
+[]byte          = any cell, see Value.Bytes
+time.Time       = TIMESTAMP, DATETIME and DATE, except zero dates
+time.Duration   = TIME
+Value           = the cell itself
+sql.Scanner     = e.g. sql.NullString, gets the cell's driver.Value
+                  (always a uint64 for unsigned integers)
+pointers        = nil for NULL, otherwise any of the above
+
+NULL can only go into pointers, Value and sql.Scanner fields.
+
+*/
+
+var (
+	ErrInvalidUnmarshalTarget = errors.New("unmarshal target must be a non-nil pointer to a struct")
+	ErrUnknownColumn          = errors.New("no column with that name")
+)
+
+// Which column and field an unmarshal failed on
+type UnmarshalError struct {
+	Column    string
+	Type      MysqlType
+	Field     string
+	FieldType reflect.Type
+	Err       error
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("cannot unmarshal column %q (%v) into field %v of type %v: %v",
+		e.Column, e.Type, e.Field, e.FieldType, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	valueType    = reflect.TypeOf((*Value)(nil)).Elem()
+	scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	bytesType    = reflect.TypeOf([]byte(nil))
+)
+
+func UnmarshalRow(tableMap *TableMapEvent, row RowImage, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w, got %T", ErrInvalidUnmarshalTarget, dst)
+	}
+	v = v.Elem()
+
+	columns := make(map[string]int, len(tableMap.ColumnTypes))
+	for i := range tableMap.ColumnTypes {
+		if name := tableMap.ColumnName(i); name != "" {
+			columns[name] = i
+		}
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		name, ok := field.Tag.Lookup("binlog")
+		if !ok || name == "-" {
+			continue
+		}
+
+		columnIndex, ok := columns[name]
+		if !ok {
+			return &UnmarshalError{
+				Column:    name,
+				Field:     field.Name,
+				FieldType: field.Type,
+				Err:       fmt.Errorf("%w in %v.%v", ErrUnknownColumn, tableMap.DatabaseName, tableMap.TableName),
+			}
+		}
+
+		if columnIndex >= len(row) || row[columnIndex] == nil {
+			continue
+		}
+
+		if !v.Field(i).CanSet() {
+			return &UnmarshalError{
+				Column:    name,
+				Type:      row[columnIndex].MysqlType(),
+				Field:     field.Name,
+				FieldType: field.Type,
+				Err:       fmt.Errorf("%w: field is not exported", ErrInvalidConversion),
+			}
+		}
+
+		if err := unmarshalValue(row[columnIndex], v.Field(i)); err != nil {
+			return &UnmarshalError{
+				Column:    name,
+				Type:      row[columnIndex].MysqlType(),
+				Field:     field.Name,
+				FieldType: field.Type,
+				Err:       err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// driver.Value has no uint64 and makes the unsigned integers above
+// math.MaxInt64 strings, a Scanner gets all of them as uint64 so a
+// column always scans as the same type (database/sql drivers pass
+// uint64 to Scan too)
+func scanValue(cell Value) (interface{}, error) {
+	if u, ok := cell.(UnsignedNumberRowImageCell); ok {
+		return uint64(u), nil
+	}
+
+	return cell.Value()
+}
+
+func unmarshalValue(cell Value, dst reflect.Value) error {
+	if dst.Type() == valueType {
+		dst.Set(reflect.ValueOf(cell))
+		return nil
+	}
+
+	if dst.Addr().Type().Implements(scannerType) {
+		driverValue, err := scanValue(cell)
+		if err != nil {
+			return err
+		}
+
+		return dst.Addr().Interface().(sql.Scanner).Scan(driverValue)
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		if cell.IsNull() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+
+		elem := reflect.New(dst.Type().Elem())
+		if err := unmarshalValue(cell, elem.Elem()); err != nil {
+			return err
+		}
+
+		dst.Set(elem)
+		return nil
+	}
+
+	if cell.IsNull() {
+		return ErrNullValue
+	}
+
+	switch dst.Type() {
+	case timeType:
+		t, err := cell.Time()
+		if err != nil {
+			return err
+		}
+
+		dst.Set(reflect.ValueOf(t))
+		return nil
+
+	case durationType:
+		timeCell, ok := cell.(TimeRowImageCell)
+		if !ok {
+			return ErrInvalidConversion
+		}
+
+		dst.SetInt(int64(timeCell.Duration()))
+		return nil
+
+	case bytesType:
+		b := cell.Bytes()
+		dst.SetBytes(append([]byte(nil), b...))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := cell.Int64()
+		if err != nil {
+			return err
+		}
+
+		if dst.OverflowInt(i) {
+			return ErrValueOutOfRange
+		}
+
+		dst.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := cell.Uint64()
+		if err != nil {
+			return err
+		}
+
+		if dst.OverflowUint(u) {
+			return ErrValueOutOfRange
+		}
+
+		dst.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := cell.Float64()
+		if err != nil {
+			return err
+		}
+
+		if dst.Kind() == reflect.Float32 && math.Abs(f) > math.MaxFloat32 {
+			return ErrValueOutOfRange
+		}
+
+		dst.SetFloat(f)
+
+	case reflect.Bool:
+		i, err := cell.Int64()
+		if err != nil {
+			return err
+		}
+
+		dst.SetBool(i != 0)
+
+	case reflect.String:
+		if bytesCell, ok := cell.(BytesRowImageCell); ok {
+			s, err := bytesCell.Text()
+			if err != nil {
+				return err
+			}
+
+			dst.SetString(s)
+			return nil
+		}
+
+		dst.SetString(cell.String())
+
+	default:
+		return ErrInvalidConversion
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package binlog
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/granicus/mysql-binlog-go/date"
+	"github.com/stretchr/testify/assert"
+)
+
+func testUnmarshalTableMap(names ...string) *TableMapEvent {
+	schema := &TableSchema{}
+	for _, name := range names {
+		schema.Columns = append(schema.Columns, ColumnSchema{Name: name})
+	}
+
+	return &TableMapEvent{
+		DatabaseName:    "db",
+		TableName:       "t",
+		NumberOfColumns: uint64(len(names)),
+		ColumnTypes:     make([]MysqlType, len(names)),
+		Schema:          schema,
+	}
+}
+
+func TestUnmarshalRow(t *testing.T) {
+	type user struct {
+		Id        uint32         `binlog:"id"`
+		Name      string         `binlog:"name"`
+		Nickname  *string        `binlog:"nickname"`
+		Email     sql.NullString `binlog:"email"`
+		Admin     bool           `binlog:"admin"`
+		Avatar    []byte         `binlog:"avatar"`
+		CreatedAt time.Time      `binlog:"created_at"`
+		Raw       Value          `binlog:"balance"`
+		Skipped   int            `binlog:"-"`
+		Untagged  int
+		Unused    int `binlog:"unused"`
+	}
+
+	tableMap := testUnmarshalTableMap(
+		"id", "name", "nickname", "email", "admin", "avatar", "created_at", "balance", "unused")
+
+	row := RowImage{
+		UnsignedNumberRowImageCell(7),
+		BytesRowImageCell{Type: MYSQL_TYPE_VARCHAR, Charset: "latin1", Data: []byte{'J', 'o', 0xeb}},
+		NewNullRowImageCell(MYSQL_TYPE_VARCHAR),
+		BytesRowImageCell{Type: MYSQL_TYPE_VARCHAR, Data: []byte("joe@example.com")},
+		NumberRowImageCell(1),
+		BytesRowImageCell{Type: MYSQL_TYPE_BLOB, Charset: "binary", Data: []byte{0x00, 0xff}},
+		DatetimeRowImageCell(date.NewMysqlDatetime(2019, 3, 5, 14, 25, 7)),
+		DecimalRowImageCell{Precision: 10, Scale: 2, Digits: "12.50"},
+		nil, // not in the row image
+	}
+
+	u := user{Nickname: new(string), Skipped: 1, Untagged: 2, Unused: 3}
+	assert.NoError(t, UnmarshalRow(tableMap, row, &u))
+
+	assert.Equal(t, uint32(7), u.Id)
+	assert.Equal(t, "Joë", u.Name)
+	assert.Nil(t, u.Nickname)
+	assert.Equal(t, sql.NullString{String: "joe@example.com", Valid: true}, u.Email)
+	assert.True(t, u.Admin)
+	assert.Equal(t, []byte{0x00, 0xff}, u.Avatar)
+	assert.Equal(t, time.Date(2019, 3, 5, 14, 25, 7, 0, time.UTC), u.CreatedAt)
+	assert.Equal(t, row[7], u.Raw)
+	assert.Equal(t, 1, u.Skipped)
+	assert.Equal(t, 2, u.Untagged)
+	assert.Equal(t, 3, u.Unused)
+}
+
+func TestUnmarshalRowErrors(t *testing.T) {
+	tableMap := testUnmarshalTableMap("id")
+
+	var small struct {
+		Id int8 `binlog:"id"`
+	}
+	err := UnmarshalRow(tableMap, RowImage{NumberRowImageCell(300)}, &small)
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+	assert.EqualError(t, err,
+		`cannot unmarshal column "id" (MYSQL_TYPE_LONGLONG) into field Id of type int8: value out of range`)
+
+	err = UnmarshalRow(tableMap, RowImage{NewNullRowImageCell(MYSQL_TYPE_LONG)}, &small)
+	assert.ErrorIs(t, err, ErrNullValue)
+
+	var unknown struct {
+		Id int `binlog:"missing"`
+	}
+	err = UnmarshalRow(tableMap, RowImage{NumberRowImageCell(1)}, &unknown)
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+
+	var wrongType struct {
+		Id time.Time `binlog:"id"`
+	}
+	err = UnmarshalRow(tableMap, RowImage{NumberRowImageCell(1)}, &wrongType)
+	assert.ErrorIs(t, err, ErrInvalidConversion)
+
+	err = UnmarshalRow(tableMap, RowImage{TimestampRowImageCell{Type: MYSQL_TYPE_TIMESTAMP}}, &wrongType)
+	assert.ErrorIs(t, err, date.ErrZeroDate)
+
+	assert.ErrorIs(t, UnmarshalRow(tableMap, RowImage{}, small), ErrInvalidUnmarshalTarget)
+}
+
+type testTypeScanner struct {
+	value interface{}
+}
+
+func (s *testTypeScanner) Scan(value interface{}) error {
+	s.value = value
+	return nil
+}
+
+func TestUnmarshalRowUnsignedScanner(t *testing.T) {
+	type counter struct {
+		Count testTypeScanner `binlog:"count"`
+	}
+
+	tableMap := testUnmarshalTableMap("count")
+
+	// Both sides of math.MaxInt64 scan as uint64
+	for _, u := range []uint64{math.MaxInt64, math.MaxInt64 + 1} {
+		var c counter
+		assert.NoError(t, UnmarshalRow(tableMap, RowImage{UnsignedNumberRowImageCell(u)}, &c))
+		assert.Equal(t, u, c.Count.value)
+	}
+
+	// Which database/sql's own Scanners accept
+	var n struct {
+		Count sql.NullInt64 `binlog:"count"`
+	}
+
+	assert.NoError(t, UnmarshalRow(tableMap, RowImage{UnsignedNumberRowImageCell(5)}, &n))
+	assert.Equal(t, sql.NullInt64{Int64: 5, Valid: true}, n.Count)
+}
@@ -0,0 +1,496 @@
+package binlog
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/granicus/mysql-binlog-go/date"
+)
+
+/*
+VALUES
+======
+
+Every row image cell implements Value, so callers don't need a
+type switch to get at the data. The conversions only succeed when
+they can't lose information: a DOUBLE is not an int64, a DECIMAL
+is an int64 only when it has no fractional part, and an UNSIGNED
+BIGINT above math.MaxInt64 is out of range, as is an integer
+beyond 2^53 as a float64. Anything else returns a *ConversionError.
+DECIMAL's Float64 is the one exception, it rounds.
+
+MysqlType is the type the cell was decoded as. Integers all report
+MYSQL_TYPE_LONGLONG and DATETIME and TIME report their V2 type; use
+TableMapEvent.ColumnTypes for the exact column type.
+
+DATE and DATETIME have no time zone, so Time returns them in UTC.
+Zero dates (0000-00-00) and the zero TIMESTAMP can't be converted
+to a time.Time.
+
+Value (driver.Valuer) returns int64, float64, []byte, string or
+time.Time, so cells can be passed straight to database/sql.
+
+*/
+
+type Value interface {
+	IsNull() bool
+	MysqlType() MysqlType
+	Int64() (int64, error)
+	Uint64() (uint64, error)
+	Float64() (float64, error)
+	String() string
+	Bytes() []byte
+	Time() (time.Time, error)
+	driver.Valuer
+}
+
+func conversionError(v Value, to string, err error) error {
+	return &ConversionError{Type: v.MysqlType(), To: to, Err: err}
+}
+
+func invalidInt64(v Value) (int64, error) {
+	return 0, conversionError(v, "int64", ErrInvalidConversion)
+}
+
+func invalidUint64(v Value) (uint64, error) {
+	return 0, conversionError(v, "uint64", ErrInvalidConversion)
+}
+
+func invalidFloat64(v Value) (float64, error) {
+	return 0, conversionError(v, "float64", ErrInvalidConversion)
+}
+
+func invalidTime(v Value) (time.Time, error) {
+	return time.Time{}, conversionError(v, "time.Time", ErrInvalidConversion)
+}
+
+// Integers above this don't all have a float64
+const maxExactFloat64 = 1 << 53
+
+func uint64ToFloat64(v Value, u uint64) (float64, error) {
+	if u > maxExactFloat64 {
+		return 0, conversionError(v, "float64", ErrValueOutOfRange)
+	}
+
+	return float64(u), nil
+}
+
+func uint64ToInt64(v Value, u uint64) (int64, error) {
+	if u > math.MaxInt64 {
+		return 0, conversionError(v, "int64", ErrValueOutOfRange)
+	}
+
+	return int64(u), nil
+}
+
+// NULL
+
+func (c NullRowImageCell) IsNull() bool         { return true }
+func (c NullRowImageCell) MysqlType() MysqlType { return MysqlType(c) }
+func (c NullRowImageCell) String() string       { return "NULL" }
+func (c NullRowImageCell) Bytes() []byte        { return nil }
+
+func (c NullRowImageCell) Int64() (int64, error) {
+	return 0, conversionError(c, "int64", ErrNullValue)
+}
+
+func (c NullRowImageCell) Uint64() (uint64, error) {
+	return 0, conversionError(c, "uint64", ErrNullValue)
+}
+
+func (c NullRowImageCell) Float64() (float64, error) {
+	return 0, conversionError(c, "float64", ErrNullValue)
+}
+
+func (c NullRowImageCell) Time() (time.Time, error) {
+	return time.Time{}, conversionError(c, "time.Time", ErrNullValue)
+}
+
+func (c NullRowImageCell) Value() (driver.Value, error) {
+	return nil, nil
+}
+
+// TINYINT, SMALLINT, MEDIUMINT, INT, BIGINT and YEAR
+
+func (c NumberRowImageCell) IsNull() bool             { return false }
+func (c NumberRowImageCell) MysqlType() MysqlType     { return MYSQL_TYPE_LONGLONG }
+func (c NumberRowImageCell) Int64() (int64, error)    { return int64(c), nil }
+func (c NumberRowImageCell) String() string           { return strconv.FormatInt(int64(c), 10) }
+func (c NumberRowImageCell) Bytes() []byte            { return []byte(c.String()) }
+func (c NumberRowImageCell) Time() (time.Time, error) { return invalidTime(c) }
+
+func (c NumberRowImageCell) Uint64() (uint64, error) {
+	if c < 0 {
+		return 0, conversionError(c, "uint64", ErrValueOutOfRange)
+	}
+
+	return uint64(c), nil
+}
+
+func (c NumberRowImageCell) Float64() (float64, error) {
+	if c > maxExactFloat64 || c < -maxExactFloat64 {
+		return 0, conversionError(c, "float64", ErrValueOutOfRange)
+	}
+
+	return float64(c), nil
+}
+
+func (c NumberRowImageCell) Value() (driver.Value, error) {
+	return int64(c), nil
+}
+
+func (c UnsignedNumberRowImageCell) IsNull() bool             { return false }
+func (c UnsignedNumberRowImageCell) MysqlType() MysqlType     { return MYSQL_TYPE_LONGLONG }
+func (c UnsignedNumberRowImageCell) Int64() (int64, error)    { return uint64ToInt64(c, uint64(c)) }
+func (c UnsignedNumberRowImageCell) Uint64() (uint64, error)  { return uint64(c), nil }
+func (c UnsignedNumberRowImageCell) String() string           { return strconv.FormatUint(uint64(c), 10) }
+func (c UnsignedNumberRowImageCell) Bytes() []byte            { return []byte(c.String()) }
+func (c UnsignedNumberRowImageCell) Time() (time.Time, error) { return invalidTime(c) }
+
+func (c UnsignedNumberRowImageCell) Float64() (float64, error) {
+	return uint64ToFloat64(c, uint64(c))
+}
+
+// driver.Value has no uint64, so values above math.MaxInt64 are strings
+func (c UnsignedNumberRowImageCell) Value() (driver.Value, error) {
+	if c > math.MaxInt64 {
+		return c.String(), nil
+	}
+
+	return int64(c), nil
+}
+
+// FLOAT and DOUBLE
+
+func (c FloatingPointNumberRowImageCell) IsNull() bool              { return false }
+func (c FloatingPointNumberRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_FLOAT }
+func (c FloatingPointNumberRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c FloatingPointNumberRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c FloatingPointNumberRowImageCell) Float64() (float64, error) { return float64(c), nil }
+func (c FloatingPointNumberRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+func (c FloatingPointNumberRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+
+func (c FloatingPointNumberRowImageCell) String() string {
+	return strconv.FormatFloat(float64(c), 'g', -1, 32)
+}
+
+func (c FloatingPointNumberRowImageCell) Value() (driver.Value, error) {
+	return float64(c), nil
+}
+
+func (c LargeFloatingPointNumberRowImageCell) IsNull() bool              { return false }
+func (c LargeFloatingPointNumberRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_DOUBLE }
+func (c LargeFloatingPointNumberRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c LargeFloatingPointNumberRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c LargeFloatingPointNumberRowImageCell) Float64() (float64, error) { return float64(c), nil }
+func (c LargeFloatingPointNumberRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+func (c LargeFloatingPointNumberRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+
+func (c LargeFloatingPointNumberRowImageCell) String() string {
+	return strconv.FormatFloat(float64(c), 'g', -1, 64)
+}
+
+func (c LargeFloatingPointNumberRowImageCell) Value() (driver.Value, error) {
+	return float64(c), nil
+}
+
+// DECIMAL
+
+func (c DecimalRowImageCell) IsNull() bool             { return false }
+func (c DecimalRowImageCell) MysqlType() MysqlType     { return MYSQL_TYPE_NEWDECIMAL }
+func (c DecimalRowImageCell) Bytes() []byte            { return []byte(c.Digits) }
+func (c DecimalRowImageCell) Time() (time.Time, error) { return invalidTime(c) }
+
+// Only for values without a fractional part
+func (c DecimalRowImageCell) integer(to string) (*big.Int, error) {
+	r := c.Rat()
+	if r == nil || !r.IsInt() {
+		return nil, conversionError(c, to, ErrInvalidConversion)
+	}
+
+	return r.Num(), nil
+}
+
+func (c DecimalRowImageCell) Int64() (int64, error) {
+	i, err := c.integer("int64")
+	if err != nil {
+		return 0, err
+	}
+
+	if !i.IsInt64() {
+		return 0, conversionError(c, "int64", ErrValueOutOfRange)
+	}
+
+	return i.Int64(), nil
+}
+
+func (c DecimalRowImageCell) Uint64() (uint64, error) {
+	i, err := c.integer("uint64")
+	if err != nil {
+		return 0, err
+	}
+
+	if !i.IsUint64() {
+		return 0, conversionError(c, "uint64", ErrValueOutOfRange)
+	}
+
+	return i.Uint64(), nil
+}
+
+// May lose precision, use Rat or String to avoid that
+func (c DecimalRowImageCell) Float64() (float64, error) {
+	r := c.Rat()
+	if r == nil {
+		return invalidFloat64(c)
+	}
+
+	f, _ := r.Float64()
+	return f, nil
+}
+
+func (c DecimalRowImageCell) Value() (driver.Value, error) {
+	return c.Digits, nil
+}
+
+// BIT
+
+func (c BitRowImageCell) IsNull() bool              { return false }
+func (c BitRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_BIT }
+func (c BitRowImageCell) Int64() (int64, error)     { return uint64ToInt64(c, c.Bits) }
+func (c BitRowImageCell) Uint64() (uint64, error)   { return c.Bits, nil }
+func (c BitRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c BitRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+
+// Binary digits, e.g. 0000000101 for BIT(10)
+func (c BitRowImageCell) String() string {
+	return fmt.Sprintf("%0*b", int(c.Length), c.Bits)
+}
+
+// Big endian, in as many bytes as the column takes up (like MySQL
+// returns it)
+func (c BitRowImageCell) Bytes() []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, c.Bits)
+
+	return b[8-(int(c.Length)+7)/8:]
+}
+
+func (c BitRowImageCell) Value() (driver.Value, error) {
+	return c.Bytes(), nil
+}
+
+// ENUM and SET
+
+func (c EnumRowImageCell) IsNull() bool              { return false }
+func (c EnumRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_ENUM }
+func (c EnumRowImageCell) Int64() (int64, error)     { return int64(c.Index), nil }
+func (c EnumRowImageCell) Uint64() (uint64, error)   { return uint64(c.Index), nil }
+func (c EnumRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c EnumRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+func (c EnumRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+
+// The member, or the index when the members aren't known
+func (c EnumRowImageCell) String() string {
+	if c.Member == "" && c.Index > 0 {
+		return strconv.FormatUint(uint64(c.Index), 10)
+	}
+
+	return c.Member
+}
+
+func (c EnumRowImageCell) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+func (c SetRowImageCell) IsNull() bool              { return false }
+func (c SetRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_SET }
+func (c SetRowImageCell) Int64() (int64, error)     { return uint64ToInt64(c, c.Bitmask) }
+func (c SetRowImageCell) Uint64() (uint64, error)   { return c.Bitmask, nil }
+func (c SetRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c SetRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+func (c SetRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+
+// Comma separated members, or the bitmask when the members aren't known
+func (c SetRowImageCell) String() string {
+	if c.Values == nil {
+		return strconv.FormatUint(c.Bitmask, 10)
+	}
+
+	return strings.Join(c.Values, ",")
+}
+
+func (c SetRowImageCell) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+// Temporal types
+
+const timestampFormat = "2006-01-02 15:04:05.999999"
+
+const zeroTimestamp = "0000-00-00 00:00:00"
+
+func (c TimestampRowImageCell) IsNull() bool              { return false }
+func (c TimestampRowImageCell) MysqlType() MysqlType      { return c.Type }
+func (c TimestampRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c TimestampRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c TimestampRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c TimestampRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+func (c TimestampRowImageCell) IsZero() bool              { return c.Timestamp.IsZero() }
+
+func (c TimestampRowImageCell) Time() (time.Time, error) {
+	if c.IsZero() {
+		return time.Time{}, conversionError(c, "time.Time", date.ErrZeroDate)
+	}
+
+	return c.Timestamp, nil
+}
+
+// In UTC
+func (c TimestampRowImageCell) String() string {
+	if c.IsZero() {
+		return zeroTimestamp
+	}
+
+	return c.Timestamp.UTC().Format(timestampFormat)
+}
+
+// The zero TIMESTAMP is a string
+func (c TimestampRowImageCell) Value() (driver.Value, error) {
+	if c.IsZero() {
+		return c.String(), nil
+	}
+
+	return c.Timestamp, nil
+}
+
+func (c DateRowImageCell) IsNull() bool              { return false }
+func (c DateRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_DATE }
+func (c DateRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c DateRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c DateRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c DateRowImageCell) String() string            { return date.MysqlDate(c).String() }
+func (c DateRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+
+func (c DateRowImageCell) Time() (time.Time, error) {
+	t, err := date.MysqlDate(c).Time(time.UTC)
+	if err != nil {
+		return time.Time{}, conversionError(c, "time.Time", err)
+	}
+
+	return t, nil
+}
+
+// Zero dates are strings
+func (c DateRowImageCell) Value() (driver.Value, error) {
+	if t, err := c.Time(); err == nil {
+		return t, nil
+	}
+
+	return c.String(), nil
+}
+
+func (c DatetimeRowImageCell) IsNull() bool              { return false }
+func (c DatetimeRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_DATETIME_V2 }
+func (c DatetimeRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c DatetimeRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c DatetimeRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c DatetimeRowImageCell) String() string            { return date.MysqlDatetime(c).String() }
+func (c DatetimeRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+
+func (c DatetimeRowImageCell) Time() (time.Time, error) {
+	t, err := date.MysqlDatetime(c).Time(time.UTC)
+	if err != nil {
+		return time.Time{}, conversionError(c, "time.Time", err)
+	}
+
+	return t, nil
+}
+
+// Zero dates are strings
+func (c DatetimeRowImageCell) Value() (driver.Value, error) {
+	if t, err := c.Time(); err == nil {
+		return t, nil
+	}
+
+	return c.String(), nil
+}
+
+// TIME is a duration rather than a point in time
+func (c TimeRowImageCell) IsNull() bool              { return false }
+func (c TimeRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_TIME_V2 }
+func (c TimeRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c TimeRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c TimeRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c TimeRowImageCell) String() string            { return date.MysqlTime(c).String() }
+func (c TimeRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+func (c TimeRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+func (c TimeRowImageCell) Duration() time.Duration   { return date.MysqlTime(c).Duration() }
+
+func (c TimeRowImageCell) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+// CHAR, VARCHAR, BINARY, VARBINARY, TEXT and BLOB
+
+func (c BytesRowImageCell) IsNull() bool              { return false }
+func (c BytesRowImageCell) MysqlType() MysqlType      { return c.Type }
+func (c BytesRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c BytesRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c BytesRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c BytesRowImageCell) Bytes() []byte             { return c.Data }
+func (c BytesRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+
+// Binary values are []byte, text is a UTF-8 string
+func (c BytesRowImageCell) Value() (driver.Value, error) {
+	if c.IsBinary() {
+		return c.Data, nil
+	}
+
+	s, err := c.Text()
+	if err != nil {
+		return nil, conversionError(c, "string", err)
+	}
+
+	return s, nil
+}
+
+// JSON
+
+func (c JSONRowImageCell) IsNull() bool              { return false }
+func (c JSONRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_JSON }
+func (c JSONRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c JSONRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c JSONRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c JSONRowImageCell) Bytes() []byte             { return []byte(c.String()) }
+func (c JSONRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+
+func (c JSONRowImageCell) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+// GEOMETRY
+
+func (c GeometryRowImageCell) IsNull() bool              { return false }
+func (c GeometryRowImageCell) MysqlType() MysqlType      { return MYSQL_TYPE_GEOMETRY }
+func (c GeometryRowImageCell) Int64() (int64, error)     { return invalidInt64(c) }
+func (c GeometryRowImageCell) Uint64() (uint64, error)   { return invalidUint64(c) }
+func (c GeometryRowImageCell) Float64() (float64, error) { return invalidFloat64(c) }
+func (c GeometryRowImageCell) Time() (time.Time, error)  { return invalidTime(c) }
+
+// MySQL's internal format: 4 byte little endian SRID, then the WKB
+func (c GeometryRowImageCell) Bytes() []byte {
+	b := make([]byte, 4, 4+len(c.WKB))
+	binary.LittleEndian.PutUint32(b, c.SRID)
+
+	return append(b, c.WKB...)
+}
+
+func (c GeometryRowImageCell) Value() (driver.Value, error) {
+	return c.Bytes(), nil
+}
@@ -0,0 +1,124 @@
+package binlog
+
+import (
+	"database/sql/driver"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/granicus/mysql-binlog-go/date"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueConversions(t *testing.T) {
+	var v Value = NumberRowImageCell(-5)
+	i, err := v.Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-5), i)
+
+	_, err = v.Uint64()
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+
+	v = UnsignedNumberRowImageCell(math.MaxUint64)
+	_, err = v.Int64()
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+
+	v = LargeFloatingPointNumberRowImageCell(1.5)
+	_, err = v.Int64()
+	assert.ErrorIs(t, err, ErrInvalidConversion)
+	assert.Equal(t, "1.5", v.String())
+
+	v = DecimalRowImageCell{Precision: 5, Scale: 2, Digits: "-12.00"}
+	i, err = v.Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-12), i)
+
+	v = DecimalRowImageCell{Precision: 5, Scale: 2, Digits: "12.50"}
+	_, err = v.Int64()
+	assert.ErrorIs(t, err, ErrInvalidConversion)
+
+	f, err := v.Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, 12.5, f)
+
+	v = NewNullRowImageCell(MYSQL_TYPE_LONG)
+	assert.True(t, v.IsNull())
+	assert.Equal(t, MYSQL_TYPE_LONG, v.MysqlType())
+	_, err = v.Int64()
+	assert.ErrorIs(t, err, ErrNullValue)
+
+	v = BytesRowImageCell{Type: MYSQL_TYPE_VARCHAR, Data: []byte("x")}
+	_, err = v.Time()
+	assert.ErrorIs(t, err, ErrInvalidConversion)
+	assert.EqualError(t, err, "cannot convert MYSQL_TYPE_VARCHAR value to time.Time: invalid value conversion")
+}
+
+func TestValueTime(t *testing.T) {
+	v := DatetimeRowImageCell(date.NewMysqlDatetime(2019, 3, 5, 14, 25, 7))
+	tm, err := v.Time()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, 3, 5, 14, 25, 7, 0, time.UTC), tm)
+
+	_, err = DateRowImageCell(date.NewMysqlDate(0, 0, 0)).Time()
+	assert.ErrorIs(t, err, date.ErrZeroDate)
+
+	timestamp := TimestampRowImageCell{Type: MYSQL_TYPE_TIMESTAMP, Timestamp: time.Unix(1500000000, 0)}
+	tm, err = timestamp.Time()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500000000), tm.Unix())
+	assert.Equal(t, MYSQL_TYPE_TIMESTAMP, timestamp.MysqlType())
+	assert.Equal(t, "2017-07-14 02:40:00", timestamp.String())
+
+	zero := TimestampRowImageCell{Type: MYSQL_TYPE_TIMESTAMP_V2}
+	assert.True(t, zero.IsZero())
+	assert.Equal(t, "0000-00-00 00:00:00", zero.String())
+	_, err = zero.Time()
+	assert.ErrorIs(t, err, date.ErrZeroDate)
+}
+
+func TestDriverValue(t *testing.T) {
+	cases := []struct {
+		value    Value
+		expected driver.Value
+	}{
+		{NewNullRowImageCell(MYSQL_TYPE_LONG), nil},
+		{NumberRowImageCell(-1), int64(-1)},
+		{UnsignedNumberRowImageCell(math.MaxUint64), "18446744073709551615"},
+		{FloatingPointNumberRowImageCell(0.5), float64(0.5)},
+		{DecimalRowImageCell{Digits: "1.10"}, "1.10"},
+		{BitRowImageCell{Length: 10, Bits: 0x201}, []byte{0x02, 0x01}},
+		{EnumRowImageCell{Index: 2, Member: "b"}, "b"},
+		{SetRowImageCell{Bitmask: 5, Values: []string{"a", "c"}}, "a,c"},
+		{BytesRowImageCell{Charset: "latin1", Data: []byte{0xe9}}, "é"},
+		{BytesRowImageCell{Charset: "binary", Data: []byte{0xe9}}, []byte{0xe9}},
+		{DateRowImageCell(date.NewMysqlDate(0, 0, 0)), "0000-00-00"},
+		{TimestampRowImageCell{Type: MYSQL_TYPE_TIMESTAMP}, "0000-00-00 00:00:00"},
+		{TimeRowImageCell(date.NewMysqlTimeWithFraction(true, 1, 0, 0, 0, 0)), "-01:00:00"},
+		{JSONRowImageCell{Data: []interface{}{int64(1)}}, "[1]"},
+	}
+
+	for _, c := range cases {
+		v, err := c.value.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, v)
+	}
+}
+
+func TestIntegerFloat64Precision(t *testing.T) {
+	f, err := NumberRowImageCell(-1 << 53).Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(-1<<53), f)
+
+	_, err = NumberRowImageCell(1<<53 + 1).Float64()
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+
+	_, err = NumberRowImageCell(-1<<53 - 1).Float64()
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+
+	f, err = UnsignedNumberRowImageCell(1 << 53).Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1<<53), f)
+
+	_, err = UnsignedNumberRowImageCell(1<<53 + 1).Float64()
+	assert.ErrorIs(t, err, ErrValueOutOfRange)
+}
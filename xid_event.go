@@ -0,0 +1,30 @@
+package binlog
+
+import (
+	. "github.com/granicus/mysql-binlog-go/deserialization"
+)
+
+// Marks the commit of a transaction on a transactional storage engine
+type XidEvent struct {
+	Xid uint64
+}
+
+/*
+XID EVENT DATA
+==============
+
+8 bytes = xid
+
+*/
+
+func (b *Binlog) DeserializeXidEvent(header *EventHeader) (EventData, error) {
+	e := new(XidEvent)
+	var err error
+
+	e.Xid, err = ReadUint64(b.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}